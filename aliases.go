@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// teamAliases maps alternate team names from GOELF_TEAM_ALIASES to their
+// canonical names, as written in the file
+var teamAliases = map[string]string{}
+
+// teamAliasLookup is teamAliases keyed by the normalized alias
+var teamAliasLookup = map[string]string{}
+
+// loadTeamAliases reads a JSON object of alias to canonical team name, e.g.
+// {"Fehervar": "Fehérvár Enthroners"}
+func loadTeamAliases(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	lookup := make(map[string]string, len(aliases))
+	for alias, canonical := range aliases {
+		if canonical == "" {
+			return fmt.Errorf("alias %q has no canonical name", alias)
+		}
+		lookup[normalizeTeamName(alias)] = canonical
+	}
+	teamAliases = aliases
+	teamAliasLookup = lookup
+	return nil
+}
+
+// canonicalTeam resolves an alias to the canonical team name. Names without
+// an alias are returned unchanged.
+func canonicalTeam(name string) string {
+	if canonical, ok := teamAliasLookup[normalizeTeamName(name)]; ok {
+		return canonical
+	}
+	return name
+}
+
+// applyTeamAliases replaces aliased team names in the games with their
+// canonical names, so the standings never split a team
+func applyTeamAliases(schedules []Schedule) {
+	if len(teamAliasLookup) == 0 {
+		return
+	}
+
+	renamed := 0
+	for i := range schedules {
+		home, away := canonicalTeam(schedules[i].HomeTeam), canonicalTeam(schedules[i].AwayTeam)
+		if home != schedules[i].HomeTeam || away != schedules[i].AwayTeam {
+			renamed++
+		}
+		schedules[i].HomeTeam, schedules[i].AwayTeam = home, away
+	}
+	if renamed > 0 {
+		logInfof("Resolved team aliases in %d games", renamed)
+	}
+}
+
+// getAliases returns the active alias map for debugging
+func getAliases(c *gin.Context) {
+	c.JSON(http.StatusOK, teamAliases)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useTeamAliases loads the aliases from a temporary file until the test ends
+func useTeamAliases(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "aliases.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadTeamAliases(path); err != nil {
+		t.Fatalf("loadTeamAliases: %v", err)
+	}
+	t.Cleanup(func() {
+		teamAliases = map[string]string{}
+		teamAliasLookup = map[string]string{}
+	})
+}
+
+func TestTeamAliasesMergeStandings(t *testing.T) {
+	r := newTestRouter(t)
+	useTeamAliases(t, `{"Duisburg Fire": "Rhein Fire"}`)
+
+	games := append([]Schedule(nil), testGames...)
+	games[3].AwayTeam = "duisburg fire" // Matched ignoring case
+	seedGames(t, games)
+
+	var standings []DivisionData
+	decodeResponse(t, serve(r, http.MethodGet, "/api/scoreboard"), &standings)
+	if got := findStanding(standings, "Rhein Fire", teamDivisions).Record; got != "2-0" {
+		t.Errorf("Rhein Fire record %s, want 2-0 including the aliased game", got)
+	}
+	for _, division := range standings {
+		for _, team := range division.Teams {
+			if team.TeamName == "duisburg fire" || team.TeamName == "Duisburg Fire" {
+				t.Errorf("the alias %q has its own standing", team.TeamName)
+			}
+		}
+	}
+
+	var aliases map[string]string
+	decodeResponse(t, serve(r, http.MethodGet, "/api/aliases"), &aliases)
+	if aliases["Duisburg Fire"] != "Rhein Fire" {
+		t.Errorf("/api/aliases = %v, want the loaded alias", aliases)
+	}
+}
+
+func TestLoadTeamAliasesInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases.json")
+	if err := os.WriteFile(path, []byte(`{"Fire": ""}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadTeamAliases(path); err == nil {
+		t.Error("an alias without a canonical name was accepted")
+	}
+}
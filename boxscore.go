@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// boxScoreWindow limits box score fetches to games that kicked off this recently
+const boxScoreWindow = 7 * 24 * time.Hour
+
+// boxScoreMu keeps a slow box score run from overlapping with the next one
+var boxScoreMu sync.Mutex
+
+// fetchBoxScores downloads the box scores of recently played games that
+// have none yet or changed since it was fetched. Requests are spaced by
+// GOELF_BOXSCORE_INTERVAL to go easy on the upstream.
+func fetchBoxScores() {
+	if !boxScoreMu.TryLock() {
+		logWarnf("Box score fetch still running, skipping")
+		return
+	}
+	defer boxScoreMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	games, err := pendingBoxScores(ctx, time.Now())
+	if err != nil {
+		logErrorf("Error finding games for box scores: %v", err)
+		return
+	}
+	if len(games) == 0 {
+		return
+	}
+
+	limiter := time.NewTicker(config.BoxScoreInterval)
+	defer limiter.Stop()
+
+	fetched := 0
+	for i, game := range games {
+		if i > 0 {
+			select {
+			case <-limiter.C:
+			case <-ctx.Done():
+				logInfof("Box score fetch timed out after %d of %d games", fetched, len(games))
+				return
+			}
+		}
+
+		if err := fetchBoxScore(ctx, game); err != nil {
+			logErrorf("Error fetching box score for %s: %v", game.StatcrewID, err)
+			continue
+		}
+		fetched++
+	}
+	logInfof("Fetched %d of %d box scores", fetched, len(games))
+}
+
+// pendingBoxScores returns the played games of the last boxScoreWindow whose
+// box score is missing or older than the game's last change
+func pendingBoxScores(ctx context.Context, now time.Time) ([]Schedule, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT s.statcrew_id, s.slug, s.date, s.time, s.game_date, s.home_score, s.away_score
+		FROM schedule s
+		LEFT JOIN boxscore b ON b.statcrew_id = s.statcrew_id
+		WHERE b.statcrew_id IS NULL OR b.fetched_at < s.updated_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []Schedule
+	for rows.Next() {
+		var s Schedule
+		if err := rows.Scan(&s.StatcrewID, &s.Slug, &s.Date, &s.Time, &s.GameDate, &s.HomeScore, &s.AwayScore); err != nil {
+			return nil, err
+		}
+		if !gamePlayed(s, now) {
+			continue
+		}
+		if kickoff, ok := parseGameTime(s); !ok || kickoff.Before(now.Add(-boxScoreWindow)) {
+			continue
+		}
+		games = append(games, s)
+	}
+	return games, rows.Err()
+}
+
+// fetchBoxScore downloads and stores the box score of a single game. The
+// upstream payload is stored as is.
+func fetchBoxScore(ctx context.Context, game Schedule) error {
+	target := strings.NewReplacer(
+		"{id}", url.PathEscape(game.StatcrewID),
+		"{slug}", url.PathEscape(game.Slug),
+	).Replace(config.BoxScoreURL)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Referer", "https://europeanleague.football/games/schedule")
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("box score API returned HTTP %d", resp.StatusCode)
+	}
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if !json.Valid(body) {
+		return fmt.Errorf("box score API returned invalid JSON")
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO boxscore (statcrew_id, data, fetched_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(statcrew_id) DO UPDATE SET data = excluded.data, fetched_at = excluded.fetched_at`,
+		game.StatcrewID, string(body))
+	if err != nil {
+		return fmt.Errorf("storing box score: %w", err)
+	}
+	return nil
+}
+
+// getGameBoxScore returns the stored upstream box score of a game
+func getGameBoxScore(c *gin.Context) {
+	var data string
+	err := db.QueryRowContext(c.Request.Context(), "SELECT data FROM boxscore WHERE statcrew_id = ?", c.Param("id")).Scan(&data)
+	if err == sql.ErrNoRows {
+		respondError(c, http.StatusNotFound, codeNotFound, "box score not found")
+		return
+	}
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(data))
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// standingsCache holds the computed standings of each league until the
+// schedule changes. With a date based GOELF_PLAYED_MODE they also expire
+// when the next game starts or ends.
+var standingsCache struct {
+	sync.RWMutex
+	standings map[string][]DivisionData
+	expires   map[string]time.Time // Zero if the standings only change with the schedule
+}
+
+// cachedStandings returns the cached standings of a league unless they have
+// expired. The caller must hold the lock.
+func cachedStandings(league string) ([]DivisionData, bool) {
+	standings, ok := standingsCache.standings[league]
+	if !ok {
+		return nil, false
+	}
+	expires := standingsCache.expires[league]
+	return standings, expires.IsZero() || time.Now().Before(expires)
+}
+
+// getStandings returns the cached standings of the context's league,
+// computing them from the schedule if the cache is empty. The result is
+// shared and must not be modified by the caller.
+func getStandings(ctx context.Context) ([]DivisionData, error) {
+	league := leagueFromContext(ctx)
+
+	standingsCache.RLock()
+	if standings, ok := cachedStandings(league); ok {
+		defer standingsCache.RUnlock()
+		return standings, nil
+	}
+	standingsCache.RUnlock()
+
+	standingsCache.Lock()
+	defer standingsCache.Unlock()
+
+	// Another request may have filled the cache in the meantime
+	if standings, ok := cachedStandings(league); ok {
+		return standings, nil
+	}
+
+	games, err := loadStandingsGames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if standingsCache.standings == nil {
+		standingsCache.standings = make(map[string][]DivisionData)
+		standingsCache.expires = make(map[string]time.Time)
+	}
+	// Taken before computing, so a game ending meanwhile expires the result
+	standingsCache.expires[league] = nextPlayedChange(games, time.Now())
+	standingsCache.standings[league] = computeStandings(games, divisionMap(league))
+	return standingsCache.standings[league], nil
+}
+
+// getStandingsWhere computes uncached standings from the games matching the
+// filter, e.g. to show the standings after a past week
+func getStandingsWhere(ctx context.Context, keep func(Schedule) bool) ([]DivisionData, error) {
+	games, err := loadStandingsGames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []Schedule
+	for _, game := range games {
+		if keep(game) {
+			kept = append(kept, game)
+		}
+	}
+	return computeStandings(kept, divisionMap(leagueFromContext(ctx))), nil
+}
+
+// loadStandingsGames loads the schedule with the scoreboard scores merged in
+// if the scoreboard is fetched
+func loadStandingsGames(ctx context.Context) ([]Schedule, error) {
+	games, err := loadSchedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.FetchScoreboard {
+		if err := mergeScoreboard(ctx, games); err != nil {
+			return nil, err
+		}
+	}
+	return games, nil
+}
+
+// invalidateStandings drops the cached standings. It must be called whenever
+// the schedule table is written.
+func invalidateStandings() {
+	standingsCache.Lock()
+	standingsCache.standings = nil
+	standingsCache.expires = nil
+	standingsCache.Unlock()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// icsTimeLayout is the UTC date-time format used in iCalendar files
+const icsTimeLayout = "20060102T150405Z"
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// getScheduleCalendar exports all games as an iCalendar file. Each event
+// lasts the configured game duration.
+func getScheduleCalendar(c *gin.Context) {
+	games, err := loadSchedule(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+	games = filterLocation(games, c.Query("location"))
+
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(buildCalendar(games, time.Now())))
+}
+
+// buildCalendar renders the games as VEVENTs. Games without a parseable date
+// are left out.
+func buildCalendar(games []Schedule, now time.Time) string {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format+"\r\n", args...)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//goelf//European League Football//EN")
+	line("X-WR-CALNAME:European League Football")
+
+	skipped := 0
+	for _, game := range games {
+		kickoff, ok := parseGameTime(game)
+		if !ok {
+			skipped++
+			continue
+		}
+
+		summary := game.HomeTeam + " vs " + game.AwayTeam
+		if gamePlayed(game, now) {
+			summary = fmt.Sprintf("%s %d - %d %s", game.HomeTeam, game.HomeScore, game.AwayScore, game.AwayTeam)
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:%s@goelf", icsEscaper.Replace(game.StatcrewID))
+		line("DTSTAMP:%s", now.UTC().Format(icsTimeLayout))
+		line("DTSTART:%s", kickoff.UTC().Format(icsTimeLayout))
+		line("DTEND:%s", kickoff.Add(config.GameDuration).UTC().Format(icsTimeLayout))
+		line("SUMMARY:%s", icsEscaper.Replace(summary))
+		if game.Location != "" {
+			line("LOCATION:%s", icsEscaper.Replace(game.Location))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	if skipped > 0 {
+		logWarnf("Calendar export skipped %d games without a valid date", skipped)
+	}
+	return b.String()
+}
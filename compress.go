@@ -0,0 +1,171 @@
+package main
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compressors are the supported response encodings of GOELF_COMPRESSION.
+// Brotli would need a third-party encoder, so it isn't offered.
+var compressors = map[string]func(io.Writer) io.WriteCloser{
+	"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+	"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+}
+
+// compressibleTypes are the content types worth compressing, images and
+// other binary assets are already compressed
+var compressibleTypes = []string{"application/json", "text/", "application/javascript", "image/svg+xml"}
+
+// negotiateEncoding picks the encoding of GOELF_COMPRESSION with the highest
+// quality in the Accept-Encoding header. Ties go to the earlier configured
+// encoding. It returns "" if the client accepts none of them.
+func negotiateEncoding(header string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		qualities[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range config.Compression {
+		q, ok := qualities[encoding]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter holds the response back until it reaches
+// GOELF_COMPRESSION_MIN_BYTES. Smaller responses are sent as they are,
+// larger ones are compressed.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	buf      []byte
+	decided  bool
+	encoder  io.WriteCloser // nil if the response is sent uncompressed
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < config.CompressionMinBytes {
+			return len(data), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends what is buffered. A response flushed before reaching the
+// threshold stays uncompressed.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide()
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide compresses the response if it is large enough and of a
+// compressible type, and writes the buffered data
+func (w *compressWriter) decide() error {
+	w.decided = true
+	header := w.Header()
+	if len(w.buf) >= config.CompressionMinBytes && header.Get("Content-Encoding") == "" &&
+		w.Status() != http.StatusPartialContent && isCompressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.encoder = compressors[w.encoding](w.ResponseWriter)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.encoder != nil {
+		_, err := w.encoder.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// finish writes a response that stayed below the threshold or completes the
+// compressed stream
+func (w *compressWriter) finish() {
+	if !w.decided {
+		w.decide()
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+	}
+}
+
+// isCompressible reports whether a content type is worth compressing
+func isCompressible(contentType string) bool {
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressResponses compresses responses of at least
+// GOELF_COMPRESSION_MIN_BYTES with the best encoding the client accepts
+func compressResponses() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(config.Compression) == 0 || c.Request.Method == http.MethodHead || c.GetHeader("Range") != "" {
+			c.Next()
+			return
+		}
+		c.Header("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			c.Writer = w.ResponseWriter
+		}()
+		c.Next()
+	}
+}
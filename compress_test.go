@@ -0,0 +1,94 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	loadTestConfig(t)
+	config.Compression = []string{"gzip", "deflate"}
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate, gzip", "gzip"}, // Tie, the configured order wins
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"GZIP", "gzip"},
+		{"gzip;q=0", ""},
+		{"br", ""},
+		{"*", "gzip"},
+		{"*;q=0.1, deflate;q=0.5", "deflate"},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+// serveEncoded sends a GET request accepting gzip
+func serveEncoded(r http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompressSmallResponse(t *testing.T) {
+	r := newTestRouter(t)
+
+	w := serveEncoded(r, "/api/ping")
+	if encoding := w.Header().Get("Content-Encoding"); encoding != "" {
+		t.Errorf("Content-Encoding %q, want a response below GOELF_COMPRESSION_MIN_BYTES uncompressed", encoding)
+	}
+	var body map[string]bool
+	decodeResponse(t, w, &body)
+	if !body["pong"] {
+		t.Errorf("body %v, want pong", body)
+	}
+}
+
+func TestCompressLargeResponse(t *testing.T) {
+	r := newTestRouter(t)
+	seedGames(t, testGames)
+
+	w := serveEncoded(r, "/api/schedule")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", w.Code, http.StatusOK)
+	}
+	if encoding := w.Header().Get("Content-Encoding"); encoding != "gzip" {
+		t.Fatalf("Content-Encoding %q, want gzip for a response of %d bytes", encoding, w.Body.Len())
+	}
+	if vary := w.Header().Get("Vary"); vary != "Accept-Encoding" {
+		t.Errorf("Vary %q, want Accept-Encoding", vary)
+	}
+
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(body) < config.CompressionMinBytes {
+		t.Errorf("decompressed %d bytes, below the threshold of %d", len(body), config.CompressionMinBytes)
+	}
+	var data ScheduleData
+	if err := json.Unmarshal(body, &data); err != nil {
+		t.Fatalf("decompressed body: %v", err)
+	}
+	if len(data.FinishedMatches) != 2 {
+		t.Errorf("got %d finished weeks, want 2", len(data.FinishedMatches))
+	}
+}
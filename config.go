@@ -0,0 +1,501 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Config holds the runtime configuration read from GOELF_* environment variables
+type Config struct {
+	Addr        string // Listen address of the HTTP server
+	DBPath      string // SQLite database file, its directory is created if missing
+	EnableMock  bool   // Insert mock data when the initial fetch returns nothing
+	DemoMode    bool   // Serve only the mock data and never fetch from the upstream
+	AdminToken  string // Bearer token for admin endpoints, disabled if empty
+	WebDir      string // Serve templates and assets from this directory instead of the binary
+	Templates   string // Glob of the HTML templates, overriding the embedded and GOELF_WEB_DIR ones
+	TeamAliases string // JSON file mapping alternate team names to canonical ones
+	LogLevel    string // Least severe logged level: debug, info, warn or error
+
+	TrustedProxies  []string // Proxy IPs or CIDRs whose X-Forwarded-For is trusted, none by default
+	TLSCert         string   // Certificate file to serve HTTPS, requires TLSKey
+	TLSKey          string   // Private key file to serve HTTPS, requires TLSCert
+	ServerTiming    bool     // Report the handler duration of API responses in a Server-Timing header
+	MaxLimit        int      // Hard cap on the number of results any list endpoint returns
+	MaxRequestBytes int64    // API request bodies larger than this are rejected
+
+	Compression         []string // Response encodings in order of preference, none disables compression
+	CompressionMinBytes int      // Responses smaller than this are sent uncompressed
+
+	ReadHeaderTimeout time.Duration // Time to read the request headers
+	ReadTimeout       time.Duration // Time to read the whole request
+	WriteTimeout      time.Duration // Time to write the response
+	IdleTimeout       time.Duration // Time a keep-alive connection may stay idle
+	MaxHeaderBytes    int           // Maximum size of the request headers
+
+	FetchCron          string        // Cron spec of the fetch job while games are coming up
+	OffseasonFetchCron string        // Cron spec of the fetch job when no game is within the next week
+	FetchOnStart       bool          // Fetch once right after startup instead of waiting for the first cron tick
+	StartupDelay       time.Duration // Delay before the fetch on startup
+	ResyncCron         string        // Cron spec of the forced full schedule re-sync, disabled if empty
+
+	ScheduleURLs     []string // Schedule sources tried in order, later ones are fallback mirrors
+	LeagueIDs        []string // GOELF_LEAGUES, resolved into Leagues by loadLeagues
+	Leagues          []League // Tracked competitions, the first one is the primary league
+	FetchScoreboard  bool     // Also fetch the upstream scoreboard and merge its scores
+	MaxResponseBytes int64    // Upstream responses larger than this are rejected
+
+	HTTPMaxIdleConns        int           // Idle upstream connections kept in total
+	HTTPMaxIdleConnsPerHost int           // Idle upstream connections kept per host
+	HTTPIdleConnTimeout     time.Duration // How long an idle upstream connection is kept
+	TLSMinVersion           string        // Minimum TLS version of upstream requests ("1.2" or "1.3")
+
+	ScoreHistory bool // Record every score change in the score_history table
+
+	NormalizeWhitespace bool // Trim and collapse whitespace in the string fields of fetched and imported games
+	TitleCaseTeams      bool // Title-case the team names of fetched and imported games
+
+	DumpResponses bool   // Write every raw schedule and scoreboard response to DumpDir
+	DumpDir       string // Directory of the response dumps
+	DumpKeep      int    // Newest dumps kept per kind
+
+	FetchBoxScores   bool          // Fetch box scores of recently played games
+	BoxScoreURL      string        // Box score URL, {id} and {slug} are replaced with the game's
+	BoxScoreInterval time.Duration // Minimum time between two box score requests
+
+	DBJournalMode string        // SQLite journal_mode
+	DBSynchronous string        // SQLite synchronous level
+	DBBusyTimeout time.Duration // How long a write waits for a lock before failing
+	DBCacheSize   int           // SQLite cache_size, negative values are KiB
+	DBForeignKeys bool          // Enforce foreign key constraints
+
+	GameDuration time.Duration // Expected length of a game, used for the live status and calendar events
+	PlayedMode   string        // When a game counts as played: score, date or hybrid
+	DefaultSort  string        // Order of /api/schedule without ?sort=, empty for the built-in one
+	UndatedGames string        // Games without a parseable date are listed "last" or "exclude"d from date ordered listings
+
+	Timezone          string // Time zone of the formatted display dates
+	DisplayDateLayout string // Go time layout of the formatted display dates
+	DisplayLocale     string // Language of the day and month names in display dates
+
+	RetentionSeasons int    // Number of seasons to keep, 0 disables the cleanup
+	RetentionCron    string // Cron spec of the cleanup job
+
+	HealthFailureThreshold int           // Consecutive fetch failures before /healthz reports degraded
+	DBCheckInterval        time.Duration // Time between two database self-checks, 0 disables them
+	MaxDataAge             time.Duration // Time without a successful fetch before /healthz reports stale data, 0 for automatic
+
+	WebhookURL      string // Slack or Discord webhook notified about results, disabled if empty
+	WebhookTemplate string // text/template for the webhook message, executed with the game
+
+	GamesPerTeam int // Regular season games per team
+	FormGames    int // Number of recent results in the standings form
+
+	StandingsAllTeams bool // List teams without a played game at 0-0 in the standings
+
+	Favorites []string // Teams flagged as favorite when a request has no ?favorites=
+
+	Divisions        []string // Divisions in display order
+	PlayoffTeams     int      // Number of teams in the playoff bracket
+	PlayoffWildcards int      // Playoff spots for teams that didn't win their division
+}
+
+var config Config
+
+// Defaults shared with the command-line flags
+const (
+	defaultAddr               = ":7788"
+	defaultDBPath             = "./database/elf25.db"
+	defaultLogLevel           = "info"
+	defaultFetchCron          = "*/5 * * * *"
+	defaultOffseasonFetchCron = "0 */6 * * *"
+)
+
+// readConfig reads the settings from the environment without validating
+// them. Every variable is read through the env helpers, so their defaults
+// are recorded in envDefaults.
+func readConfig() Config {
+	return Config{
+		Addr:        envString("GOELF_ADDR", defaultAddr),
+		DBPath:      envString("GOELF_DB", defaultDBPath),
+		EnableMock:  envBool("GOELF_ENABLE_MOCK", false),
+		DemoMode:    envBool("GOELF_DEMO_MODE", false),
+		AdminToken:  envString("GOELF_ADMIN_TOKEN", ""),
+		WebDir:      envString("GOELF_WEB_DIR", ""),
+		Templates:   envString("GOELF_TEMPLATES", ""),
+		TeamAliases: envString("GOELF_TEAM_ALIASES", ""),
+		LogLevel:    strings.ToLower(envString("GOELF_LOG_LEVEL", defaultLogLevel)),
+
+		TrustedProxies:  envList("GOELF_TRUSTED_PROXIES", nil),
+		TLSCert:         envString("GOELF_TLS_CERT", ""),
+		TLSKey:          envString("GOELF_TLS_KEY", ""),
+		ServerTiming:    envBool("GOELF_SERVER_TIMING", false),
+		MaxLimit:        envInt("GOELF_MAX_LIMIT", 200),
+		MaxRequestBytes: int64(envInt("GOELF_MAX_REQUEST_BYTES", 1<<20)),
+
+		Compression:         envList("GOELF_COMPRESSION", []string{"gzip"}),
+		CompressionMinBytes: envInt("GOELF_COMPRESSION_MIN_BYTES", 1024),
+
+		ReadHeaderTimeout: envDuration("GOELF_READ_HEADER_TIMEOUT", 5*time.Second),
+		ReadTimeout:       envDuration("GOELF_READ_TIMEOUT", 15*time.Second),
+		WriteTimeout:      envDuration("GOELF_WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:       envDuration("GOELF_IDLE_TIMEOUT", 60*time.Second),
+		MaxHeaderBytes:    envInt("GOELF_MAX_HEADER_BYTES", 64<<10),
+
+		FetchCron:          envString("GOELF_FETCH_CRON", defaultFetchCron),
+		OffseasonFetchCron: envString("GOELF_OFFSEASON_FETCH_CRON", defaultOffseasonFetchCron),
+		FetchOnStart:       envBool("GOELF_FETCH_ON_START", true),
+		StartupDelay:       envDuration("GOELF_STARTUP_DELAY", 2*time.Second),
+		ResyncCron:         envString("GOELF_RESYNC_CRON", ""),
+
+		ScheduleURLs:     envList("GOELF_SCHEDULE_URLS", []string{"https://europeanleague.football/api/schedule"}),
+		LeagueIDs:        envList("GOELF_LEAGUES", []string{"elf"}),
+		FetchScoreboard:  envBool("GOELF_FETCH_SCOREBOARD", false),
+		MaxResponseBytes: int64(envInt("GOELF_MAX_RESPONSE_BYTES", 10<<20)),
+
+		HTTPMaxIdleConns:        envInt("GOELF_HTTP_MAX_IDLE_CONNS", 10),
+		HTTPMaxIdleConnsPerHost: envInt("GOELF_HTTP_MAX_IDLE_CONNS_PER_HOST", 4),
+		HTTPIdleConnTimeout:     envDuration("GOELF_HTTP_IDLE_CONN_TIMEOUT", 10*time.Minute),
+		TLSMinVersion:           envString("GOELF_TLS_MIN_VERSION", "1.2"),
+
+		ScoreHistory: envBool("GOELF_SCORE_HISTORY", false),
+
+		NormalizeWhitespace: envBool("GOELF_NORMALIZE_WHITESPACE", true),
+		TitleCaseTeams:      envBool("GOELF_TITLE_CASE_TEAMS", false),
+
+		DumpResponses: envBool("GOELF_DUMP_RESPONSES", false),
+		DumpDir:       envString("GOELF_DUMP_DIR", "./database/dumps"),
+		DumpKeep:      envInt("GOELF_DUMP_KEEP", 20),
+
+		FetchBoxScores:   envBool("GOELF_FETCH_BOXSCORES", false),
+		BoxScoreURL:      envString("GOELF_BOXSCORE_URL", "https://europeanleague.football/api/games/{slug}/boxscore"),
+		BoxScoreInterval: envDuration("GOELF_BOXSCORE_INTERVAL", 2*time.Second),
+
+		DBJournalMode: strings.ToUpper(envString("GOELF_DB_JOURNAL_MODE", "WAL")),
+		DBSynchronous: strings.ToUpper(envString("GOELF_DB_SYNCHRONOUS", "NORMAL")),
+		DBBusyTimeout: envDuration("GOELF_DB_BUSY_TIMEOUT", 5*time.Second),
+		DBCacheSize:   envInt("GOELF_DB_CACHE_SIZE", -2000),
+		DBForeignKeys: envBool("GOELF_DB_FOREIGN_KEYS", true),
+
+		GameDuration: envDuration("GOELF_GAME_DURATION", 3*time.Hour),
+		PlayedMode:   strings.ToLower(envString("GOELF_PLAYED_MODE", playedScore)),
+		DefaultSort:  envString("GOELF_DEFAULT_SORT", ""),
+		UndatedGames: strings.ToLower(envString("GOELF_UNDATED_GAMES", undatedLast)),
+
+		Timezone:          envString("GOELF_TIMEZONE", "Europe/Berlin"),
+		DisplayDateLayout: envString("GOELF_DISPLAY_DATE_LAYOUT", "Mon, Jan 2 · 15:04 MST"),
+		DisplayLocale:     strings.ToLower(envString("GOELF_DISPLAY_LOCALE", "en")),
+
+		RetentionSeasons: envInt("GOELF_RETENTION_SEASONS", 0),
+		RetentionCron:    envString("GOELF_RETENTION_CRON", "0 4 * * *"),
+
+		HealthFailureThreshold: envInt("GOELF_HEALTH_FAILURE_THRESHOLD", 3),
+		DBCheckInterval:        envDuration("GOELF_DB_CHECK_INTERVAL", 30*time.Second),
+		MaxDataAge:             envDuration("GOELF_MAX_DATA_AGE", 0),
+
+		WebhookURL:      envString("GOELF_WEBHOOK_URL", ""),
+		WebhookTemplate: envString("GOELF_WEBHOOK_TEMPLATE", "Final: {{.HomeTeam}} {{.HomeScore}} - {{.AwayScore}} {{.AwayTeam}}"),
+
+		GamesPerTeam: envInt("GOELF_GAMES_PER_TEAM", 12),
+		FormGames:    envInt("GOELF_FORM_GAMES", 5),
+
+		StandingsAllTeams: envBool("GOELF_STANDINGS_ALL_TEAMS", true),
+
+		Favorites: envList("GOELF_FAVORITES", nil),
+
+		Divisions:        envList("GOELF_DIVISIONS", []string{"EAST", "WEST", "NORTH", "SOUTH"}),
+		PlayoffTeams:     envInt("GOELF_PLAYOFF_TEAMS", 6),
+		PlayoffWildcards: envInt("GOELF_PLAYOFF_WILDCARDS", 2),
+	}
+}
+
+// envDefaults are the defaults of the GOELF_* variables read by readConfig,
+// listed by -help
+var envDefaults = make(map[string]string)
+
+// loadConfig reads the configuration from the environment and validates it
+func loadConfig() error {
+	config = readConfig()
+
+	for i, division := range config.Divisions {
+		config.Divisions[i] = strings.ToUpper(division)
+	}
+
+	for key, timeout := range map[string]time.Duration{
+		"GOELF_READ_HEADER_TIMEOUT": config.ReadHeaderTimeout,
+		"GOELF_READ_TIMEOUT":        config.ReadTimeout,
+		"GOELF_WRITE_TIMEOUT":       config.WriteTimeout,
+		"GOELF_IDLE_TIMEOUT":        config.IdleTimeout,
+	} {
+		if timeout <= 0 {
+			return fmt.Errorf("%s must be positive, got %v", key, timeout)
+		}
+	}
+	if config.Templates != "" {
+		matches, err := filepath.Glob(config.Templates)
+		if err != nil {
+			return fmt.Errorf("GOELF_TEMPLATES is not a valid glob: %w", err)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("GOELF_TEMPLATES %q matches no files", config.Templates)
+		}
+	}
+	if (config.TLSCert == "") != (config.TLSKey == "") {
+		return fmt.Errorf("GOELF_TLS_CERT and GOELF_TLS_KEY must be set together")
+	}
+	for key, path := range map[string]string{"GOELF_TLS_CERT": config.TLSCert, "GOELF_TLS_KEY": config.TLSKey} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	if config.MaxLimit < 1 {
+		return fmt.Errorf("GOELF_MAX_LIMIT must be positive, got %d", config.MaxLimit)
+	}
+	if config.MaxRequestBytes < 1 {
+		return fmt.Errorf("GOELF_MAX_REQUEST_BYTES must be positive, got %d", config.MaxRequestBytes)
+	}
+	for i, encoding := range config.Compression {
+		encoding = strings.ToLower(encoding)
+		switch _, ok := compressors[encoding]; {
+		case encoding == "none" && len(config.Compression) == 1:
+			config.Compression = nil
+		case encoding == "br" || encoding == "brotli":
+			return fmt.Errorf("GOELF_COMPRESSION: brotli is not supported by this build, use gzip or deflate")
+		case !ok:
+			return fmt.Errorf("GOELF_COMPRESSION must list gzip and/or deflate or be none, got %q", encoding)
+		default:
+			config.Compression[i] = encoding
+		}
+	}
+	if config.CompressionMinBytes < 1 {
+		return fmt.Errorf("GOELF_COMPRESSION_MIN_BYTES must be positive, got %d", config.CompressionMinBytes)
+	}
+	if config.MaxHeaderBytes < 1 {
+		return fmt.Errorf("GOELF_MAX_HEADER_BYTES must be positive, got %d", config.MaxHeaderBytes)
+	}
+	if _, err := cron.ParseStandard(config.FetchCron); err != nil {
+		return fmt.Errorf("GOELF_FETCH_CRON is not a valid cron spec: %w", err)
+	}
+	if _, err := cron.ParseStandard(config.OffseasonFetchCron); err != nil {
+		return fmt.Errorf("GOELF_OFFSEASON_FETCH_CRON is not a valid cron spec: %w", err)
+	}
+	if config.ResyncCron != "" {
+		if _, err := cron.ParseStandard(config.ResyncCron); err != nil {
+			return fmt.Errorf("GOELF_RESYNC_CRON is not a valid cron spec: %w", err)
+		}
+	}
+	if config.StartupDelay < 0 {
+		return fmt.Errorf("GOELF_STARTUP_DELAY must not be negative, got %v", config.StartupDelay)
+	}
+	for _, source := range config.ScheduleURLs {
+		if !validSourceURL(source) {
+			return fmt.Errorf("GOELF_SCHEDULE_URLS contains an invalid URL %q", source)
+		}
+	}
+	leagues, divisions, err := loadLeagues()
+	if err != nil {
+		return err
+	}
+	config.Leagues = leagues
+	leagueDivisions = divisions
+	if config.MaxResponseBytes < 1 {
+		return fmt.Errorf("GOELF_MAX_RESPONSE_BYTES must be positive, got %d", config.MaxResponseBytes)
+	}
+	if config.HTTPMaxIdleConns < 0 || config.HTTPMaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("GOELF_HTTP_MAX_IDLE_CONNS and GOELF_HTTP_MAX_IDLE_CONNS_PER_HOST must not be negative")
+	}
+	if config.HTTPIdleConnTimeout <= 0 {
+		return fmt.Errorf("GOELF_HTTP_IDLE_CONN_TIMEOUT must be positive, got %v", config.HTTPIdleConnTimeout)
+	}
+	if _, ok := tlsVersions[config.TLSMinVersion]; !ok {
+		return fmt.Errorf("GOELF_TLS_MIN_VERSION must be 1.2 or 1.3, got %q", config.TLSMinVersion)
+	}
+	if config.DumpKeep < 1 {
+		return fmt.Errorf("GOELF_DUMP_KEEP must be positive, got %d", config.DumpKeep)
+	}
+	if config.BoxScoreInterval <= 0 {
+		return fmt.Errorf("GOELF_BOXSCORE_INTERVAL must be positive, got %v", config.BoxScoreInterval)
+	}
+	switch config.DBJournalMode {
+	case "DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF":
+	default:
+		return fmt.Errorf("GOELF_DB_JOURNAL_MODE must be DELETE, TRUNCATE, PERSIST, MEMORY, WAL or OFF, got %q", config.DBJournalMode)
+	}
+	switch config.DBSynchronous {
+	case "OFF", "NORMAL", "FULL", "EXTRA":
+	default:
+		return fmt.Errorf("GOELF_DB_SYNCHRONOUS must be OFF, NORMAL, FULL or EXTRA, got %q", config.DBSynchronous)
+	}
+	if config.DBBusyTimeout < 0 {
+		return fmt.Errorf("GOELF_DB_BUSY_TIMEOUT must not be negative, got %v", config.DBBusyTimeout)
+	}
+	if config.TeamAliases != "" {
+		if err := loadTeamAliases(config.TeamAliases); err != nil {
+			return fmt.Errorf("GOELF_TEAM_ALIASES: %w", err)
+		}
+	}
+	tmpl, err := template.New("webhook").Parse(config.WebhookTemplate)
+	if err != nil {
+		return fmt.Errorf("GOELF_WEBHOOK_TEMPLATE is not a valid template: %w", err)
+	}
+	webhookTemplate = tmpl
+
+	if config.GamesPerTeam < 1 {
+		return fmt.Errorf("GOELF_GAMES_PER_TEAM must be positive, got %d", config.GamesPerTeam)
+	}
+	if config.FormGames < 1 {
+		return fmt.Errorf("GOELF_FORM_GAMES must be positive, got %d", config.FormGames)
+	}
+	if config.PlayoffTeams < 2 || config.PlayoffTeams > 8 {
+		return fmt.Errorf("GOELF_PLAYOFF_TEAMS must be between 2 and 8, got %d", config.PlayoffTeams)
+	}
+	if config.PlayoffWildcards < 0 || config.PlayoffWildcards > config.PlayoffTeams {
+		return fmt.Errorf("GOELF_PLAYOFF_WILDCARDS must be between 0 and GOELF_PLAYOFF_TEAMS, got %d", config.PlayoffWildcards)
+	}
+
+	// The game duration is used in several places, so reject bad values
+	// instead of silently falling back to the default
+	if value := os.Getenv("GOELF_GAME_DURATION"); value != "" {
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("GOELF_GAME_DURATION must be a duration like \"3h\", got %q", value)
+		}
+	}
+	if config.GameDuration <= 0 {
+		return fmt.Errorf("GOELF_GAME_DURATION must be positive, got %v", config.GameDuration)
+	}
+
+	switch config.PlayedMode {
+	case playedScore, playedDate, playedHybrid:
+	default:
+		return fmt.Errorf("GOELF_PLAYED_MODE must be score, date or hybrid, got %q", config.PlayedMode)
+	}
+	if _, ok := scheduleSortOrders[config.DefaultSort]; !ok && config.DefaultSort != "" {
+		return fmt.Errorf("GOELF_DEFAULT_SORT must be date, -date, gameweek or -gameweek, got %q", config.DefaultSort)
+	}
+	if config.UndatedGames != undatedLast && config.UndatedGames != undatedExclude {
+		return fmt.Errorf("GOELF_UNDATED_GAMES must be last or exclude, got %q", config.UndatedGames)
+	}
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		return fmt.Errorf("GOELF_TIMEZONE: %w", err)
+	}
+	displayLocation = location
+	if _, ok := displayLocales[config.DisplayLocale]; !ok {
+		return fmt.Errorf("GOELF_DISPLAY_LOCALE must be en or de, got %q", config.DisplayLocale)
+	}
+
+	level, ok := logLevels[config.LogLevel]
+	if !ok {
+		return fmt.Errorf("GOELF_LOG_LEVEL must be debug, info, warn or error, got %q", config.LogLevel)
+	}
+	logLevel = level
+
+	if config.HealthFailureThreshold < 1 {
+		return fmt.Errorf("GOELF_HEALTH_FAILURE_THRESHOLD must be positive, got %d", config.HealthFailureThreshold)
+	}
+	if config.DBCheckInterval < 0 {
+		return fmt.Errorf("GOELF_DB_CHECK_INTERVAL must not be negative, got %v", config.DBCheckInterval)
+	}
+	if config.MaxDataAge < 0 {
+		return fmt.Errorf("GOELF_MAX_DATA_AGE must not be negative, got %v", config.MaxDataAge)
+	}
+	if config.RetentionSeasons < 0 {
+		return fmt.Errorf("GOELF_RETENTION_SEASONS must not be negative, got %d", config.RetentionSeasons)
+	}
+
+	return nil
+}
+
+// validSourceURL reports whether an upstream URL is an absolute http(s) URL
+func validSourceURL(source string) bool {
+	u, err := url.Parse(source)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// envString reads a string environment variable, falling back to the default
+// if it is unset or empty
+func envString(key, fallback string) string {
+	envDefaults[key] = fallback
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// envBool reads a boolean environment variable, falling back to the default
+// if it is unset or invalid
+func envBool(key string, fallback bool) bool {
+	envDefaults[key] = strconv.FormatBool(fallback)
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		logWarnf("Invalid value %q for %s, using default %v", value, key, fallback)
+		return fallback
+	}
+	return b
+}
+
+// envInt reads an integer environment variable, falling back to the default
+// if it is unset or invalid
+func envInt(key string, fallback int) int {
+	envDefaults[key] = strconv.Itoa(fallback)
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		logWarnf("Invalid value %q for %s, using default %v", value, key, fallback)
+		return fallback
+	}
+	return i
+}
+
+// envList reads a comma separated environment variable, falling back to the
+// default if it is unset or empty
+func envList(key string, fallback []string) []string {
+	envDefaults[key] = strings.Join(fallback, ",")
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	if len(list) == 0 {
+		return fallback
+	}
+	return list
+}
+
+// envDuration reads a duration environment variable (e.g. "3h"), falling
+// back to the default if it is unset or invalid
+func envDuration(key string, fallback time.Duration) time.Duration {
+	envDefaults[key] = ""
+	if fallback != 0 {
+		envDefaults[key] = fallback.String()
+	}
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		logWarnf("Invalid value %q for %s, using default %v", value, key, fallback)
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getScheduleCSV exports all games as CSV, one row per game
+func getScheduleCSV(c *gin.Context) {
+	games, err := loadSchedule(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+	games = applyUndatedPolicy(filterLocation(games, c.Query("location")))
+
+	now := time.Now()
+	rows := [][]string{{"statcrew_id", "game_week", "game_date", "home_team", "away_team", "home_score", "away_score", "location", "status"}}
+	for _, game := range games {
+		rows = append(rows, []string{
+			game.StatcrewID,
+			strconv.Itoa(game.GameWeek),
+			game.GameDate,
+			game.HomeTeam,
+			game.AwayTeam,
+			strconv.Itoa(game.HomeScore),
+			strconv.Itoa(game.AwayScore),
+			game.Location,
+			gameStatus(game, now),
+		})
+	}
+
+	respondCSV(c, rows)
+}
+
+// getScoreboardCSV exports the standings as CSV, one row per team
+func getScoreboardCSV(c *gin.Context, standings []DivisionData) {
+	rows := [][]string{{"division", "position", "team", "wins", "losses", "points_for", "points_against", "point_diff", "sos", "sov", "form", "streak"}}
+	for _, data := range standings {
+		for _, team := range data.Teams {
+			rows = append(rows, []string{
+				data.Division,
+				strconv.Itoa(team.Position),
+				team.TeamName,
+				strconv.Itoa(team.Wins),
+				strconv.Itoa(team.Losses),
+				strconv.Itoa(team.PointsFor),
+				strconv.Itoa(team.PointsAgainst),
+				strconv.Itoa(team.PointDiff),
+				strconv.FormatFloat(team.SoS, 'f', 3, 64),
+				strconv.FormatFloat(team.SoV, 'f', 3, 64),
+				team.Form,
+				team.Streak,
+			})
+		}
+	}
+
+	respondCSV(c, rows)
+}
+
+// respondCSV writes the rows, the first being the header, as a CSV response
+func respondCSV(c *gin.Context, rows [][]string) {
+	c.Header("Content-Type", mimeCSV+"; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.WriteAll(rows)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Dashboard game counts
+const (
+	defaultDashboardGames = 5
+	maxDashboardGames     = 20
+)
+
+// Dashboard is everything the homepage shows, in a single response
+type Dashboard struct {
+	Upcoming  []Schedule     // Next games, soonest first
+	Results   []GameDetail   // Latest results, most recent first
+	Standings []DivisionData // Current standings
+}
+
+// dashboardCount parses a game count query parameter, capped at
+// maxDashboardGames
+func dashboardCount(c *gin.Context, key string) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return defaultDashboardGames, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a number of games", key)
+	}
+	return clampLimit(c, n, maxDashboardGames), nil
+}
+
+// getDashboard returns the next games, the latest results and the standings
+// so the homepage renders with one request. ?upcoming= and ?results= set the
+// number of games.
+func getDashboard(c *gin.Context) {
+	upcomingCount, err := dashboardCount(c, "upcoming")
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	resultsCount, err := dashboardCount(c, "results")
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	display, err := wantsDisplayDates(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	schedules, err := loadDisplaySchedule(c.Request.Context(), scheduleSortOrders["date"])
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+	stripDisplayDates(schedules, display)
+	favorites := requestFavorites(c)
+	markFavoriteGames(schedules, favorites)
+
+	standings, err := getStandings(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	dashboard := Dashboard{
+		Upcoming:  []Schedule{},
+		Results:   []GameDetail{},
+		Standings: markFavoriteStandings(standings, favorites),
+	}
+	// The dates are already reformatted, so use the played state derived
+	// when the rows were loaded
+	for _, game := range schedules {
+		if !game.played && len(dashboard.Upcoming) < upcomingCount {
+			dashboard.Upcoming = append(dashboard.Upcoming, game)
+		}
+	}
+	// Games are ordered by date, so walk backwards for the latest results
+	for i := len(schedules) - 1; i >= 0 && len(dashboard.Results) < resultsCount; i-- {
+		if schedules[i].played {
+			dashboard.Results = append(dashboard.Results, gameDetail(schedules[i], true))
+		}
+	}
+
+	respondJSON(c, http.StatusOK, dashboard)
+}
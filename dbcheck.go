@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// dbCheckTimeout bounds a single database self-check
+const dbCheckTimeout = 5 * time.Second
+
+// dbFile is the path of the database file, empty for in-memory databases
+var dbFile string
+
+// dbHealth holds the outcome of the last database self-check
+var dbHealth struct {
+	sync.Mutex
+	checked bool
+	err     error
+}
+
+// checkDB verifies that the database file still exists and that it can be
+// read and written. A write catches a full disk or a file that became read
+// only, which a ping on the open connection doesn't notice.
+func checkDB(ctx context.Context) error {
+	if dbFile != "" {
+		if _, err := os.Stat(dbFile); err != nil {
+			return fmt.Errorf("database file: %w", err)
+		}
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+	return setMetadata(ctx, metaDBCheck, time.Now().UTC().Format(time.RFC3339))
+}
+
+// recordDBCheck stores the outcome of a self-check and logs when the
+// database becomes unhealthy or recovers
+func recordDBCheck(err error) {
+	dbHealth.Lock()
+	defer dbHealth.Unlock()
+
+	switch {
+	case err != nil && (!dbHealth.checked || dbHealth.err == nil):
+		logErrorf("Database self-check failed, the database is unhealthy: %v", err)
+	case err == nil && dbHealth.checked && dbHealth.err != nil:
+		logInfof("Database self-check succeeded, the database is healthy again")
+	}
+	dbHealth.checked = true
+	dbHealth.err = err
+}
+
+// startDBCheck checks the database every GOELF_DB_CHECK_INTERVAL, so
+// /healthz notices a broken database even without API traffic
+func startDBCheck() {
+	if config.DBCheckInterval <= 0 {
+		return
+	}
+	logInfof("Database self-check enabled (every %v)", config.DBCheckInterval)
+
+	go func() {
+		ticker := time.NewTicker(config.DBCheckInterval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), dbCheckTimeout)
+			recordDBCheck(checkDB(ctx))
+			cancel()
+			<-ticker.C
+		}
+	}()
+}
+
+// databaseError returns the error of the last self-check, or pings the
+// database if the self-check is disabled or hasn't run yet
+func databaseError(ctx context.Context) error {
+	dbHealth.Lock()
+	checked, err := dbHealth.checked, dbHealth.err
+	dbHealth.Unlock()
+
+	if checked {
+		return err
+	}
+	return db.PingContext(ctx)
+}
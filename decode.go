@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// decodeTolerant decodes a JSON array of objects into out, a pointer to a
+// slice of structs. Each entry is decoded normally first. If that fails, it
+// is decoded field by field and a field that doesn't match its Go type is
+// left at its zero value instead of failing the whole payload. Entries that
+// aren't objects are skipped. Failed fields are logged once per field with
+// the number of affected entries.
+func decodeTolerant(body []byte, out interface{}) error {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return err
+	}
+
+	slice := reflect.ValueOf(out).Elem()
+	elemType := slice.Type().Elem()
+
+	failed := make(map[string]int)
+	firstErr := make(map[string]error)
+	skipped := 0
+
+	result := reflect.MakeSlice(slice.Type(), 0, len(entries))
+	for _, entry := range entries {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(entry, &fields); err != nil || fields == nil {
+			skipped++
+			continue
+		}
+
+		elem := reflect.New(elemType).Elem()
+		if err := json.Unmarshal(entry, elem.Addr().Interface()); err == nil {
+			result = reflect.Append(result, elem)
+			continue
+		}
+
+		elem = reflect.New(elemType).Elem()
+		for i := 0; i < elemType.NumField(); i++ {
+			key := strings.Split(elemType.Field(i).Tag.Get("json"), ",")[0]
+			if key == "-" {
+				continue
+			}
+			if key == "" {
+				key = elemType.Field(i).Name
+			}
+			raw, ok := lookupKey(fields, key)
+			if !ok {
+				continue
+			}
+
+			// Decode the field on its own, through the type's UnmarshalJSON
+			// if it has one, and keep it if that succeeds
+			single, _ := json.Marshal(map[string]json.RawMessage{key: raw})
+			target := reflect.New(elemType)
+			if err := json.Unmarshal(single, target.Interface()); err != nil {
+				failed[key]++
+				if firstErr[key] == nil {
+					firstErr[key] = err
+				}
+				continue
+			}
+			elem.Field(i).Set(target.Elem().Field(i))
+		}
+		result = reflect.Append(result, elem)
+	}
+	slice.Set(result)
+
+	for key, count := range failed {
+		logWarnf("Could not decode %s.%s in %d entries: %v", elemType.Name(), key, count, firstErr[key])
+	}
+	if skipped > 0 {
+		logWarnf("Skipped %d %s entries that are not JSON objects", skipped, elemType.Name())
+	}
+	return nil
+}
+
+// lookupKey finds a JSON key like encoding/json does: an exact match first,
+// then a case-insensitive one
+func lookupKey(fields map[string]json.RawMessage, key string) (json.RawMessage, bool) {
+	if raw, ok := fields[key]; ok {
+		return raw, true
+	}
+	for name, raw := range fields {
+		if strings.EqualFold(name, key) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
+
+// flexInt is an integer that the upstream sends either as a number or as a
+// string. Empty strings and null decode as zero.
+type flexInt int
+
+func (n *flexInt) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		*n = 0
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(strings.TrimSpace(s))
+		if len(data) == 0 {
+			*n = 0
+			return nil
+		}
+	}
+
+	i, err := strconv.Atoi(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*n = flexInt(i)
+	return nil
+}
+
+// UnmarshalJSON decodes an upstream scoreboard entry, accepting the scores as
+// numbers or strings
+func (s *Scoreboard) UnmarshalJSON(data []byte) error {
+	type plain Scoreboard
+	aux := struct {
+		*plain
+		HomeScore flexInt `json:"homeScore"`
+		AwayScore flexInt `json:"awayScore"`
+	}{plain: (*plain)(s)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.HomeScore = int(aux.HomeScore)
+	s.AwayScore = int(aux.AwayScore)
+	return nil
+}
+
+// UnmarshalJSON decodes an upstream game, accepting the scores as numbers or
+// strings. The upstream sends the venue as "Location"; "location" is
+// accepted too in case that ever changes, preferring whichever isn't empty.
+func (s *Schedule) UnmarshalJSON(data []byte) error {
+	type plain Schedule
+	aux := struct {
+		*plain
+		HomeScore     flexInt `json:"homeScore"`
+		AwayScore     flexInt `json:"awayScore"`
+		LowerLocation string  `json:"location"`
+	}{plain: (*plain)(s)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.HomeScore = int(aux.HomeScore)
+	s.AwayScore = int(aux.AwayScore)
+	if s.Location == "" {
+		s.Location = aux.LowerLocation
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeTolerant(t *testing.T) {
+	loadTestConfig(t)
+
+	body := []byte(`[
+		{"statcrewID": "g1", "homename": "Rhein Fire", "awayname": "Berlin Thunder", "homeScore": "28", "awayScore": 14},
+		{"statcrewID": "g2", "homename": "Nordic Storm", "awayname": "Hamburg Sea Devils", "homeScore": {"total": 3}, "awayScore": 7, "gameweek": 2},
+		"not a game",
+		{"statcrewID": "g3", "homename": "Vienna Vikings", "awayname": "Prague Lions", "gameweek": "three"}
+	]`)
+
+	var games []Schedule
+	if err := decodeTolerant(body, &games); err != nil {
+		t.Fatalf("decodeTolerant: %v", err)
+	}
+	if len(games) != 3 {
+		t.Fatalf("decoded %d games, want 3 (the string entry skipped)", len(games))
+	}
+
+	// A score sent as a quoted string is decoded like a number
+	if games[0].HomeScore != 28 || games[0].AwayScore != 14 {
+		t.Errorf("g1 score %d-%d, want 28-14", games[0].HomeScore, games[0].AwayScore)
+	}
+	// A bad field is left at zero, the rest of the entry is kept
+	if games[1].StatcrewID != "g2" || games[1].HomeScore != 0 || games[1].AwayScore != 7 || games[1].GameWeek != 2 {
+		t.Errorf("g2 = %+v, want the bad homeScore zeroed and the other fields kept", games[1])
+	}
+	if games[2].StatcrewID != "g3" || games[2].HomeTeam != "Vienna Vikings" || games[2].GameWeek != 0 {
+		t.Errorf("g3 = %+v, want the bad gameweek zeroed and the other fields kept", games[2])
+	}
+}
+
+func TestDecodeTolerantNotAnArray(t *testing.T) {
+	var games []Schedule
+	if err := decodeTolerant([]byte(`{"error": "maintenance"}`), &games); err == nil {
+		t.Error("decoding an object succeeded, want an error")
+	}
+}
+
+func TestFlexInt(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    int
+		wantErr bool
+	}{
+		{`21`, 21, false},
+		{`"21"`, 21, false},
+		{`" 7 "`, 7, false},
+		{`""`, 0, false},
+		{`null`, 0, false},
+		{`"abc"`, 0, true},
+		{`1.5`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var n flexInt
+			err := n.UnmarshalJSON([]byte(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && int(n) != tt.want {
+				t.Errorf("got %d, want %d", n, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleUnmarshalScores(t *testing.T) {
+	for _, body := range []string{
+		`{"statcrewID": "g1", "homeScore": 28, "awayScore": 14}`,
+		`{"statcrewID": "g1", "homeScore": "28", "awayScore": "14"}`,
+	} {
+		var s Schedule
+		if err := json.Unmarshal([]byte(body), &s); err != nil {
+			t.Fatalf("%s: %v", body, err)
+		}
+		if s.HomeScore != 28 || s.AwayScore != 14 {
+			t.Errorf("%s: score %d-%d, want 28-14", body, s.HomeScore, s.AwayScore)
+		}
+	}
+
+	// Missing and empty scores are games that haven't been played
+	for _, body := range []string{`{"statcrewID": "g1"}`, `{"statcrewID": "g1", "homeScore": "", "awayScore": null}`} {
+		var s Schedule
+		if err := json.Unmarshal([]byte(body), &s); err != nil {
+			t.Fatalf("%s: %v", body, err)
+		}
+		if s.HomeScore != 0 || s.AwayScore != 0 {
+			t.Errorf("%s: score %d-%d, want 0-0", body, s.HomeScore, s.AwayScore)
+		}
+	}
+}
+
+func TestScheduleUnmarshalLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"upper case key", `{"Location": "Duisburg"}`, "Duisburg"},
+		{"lower case key", `{"location": "Duisburg"}`, "Duisburg"},
+		{"empty upper case key", `{"Location": "", "location": "Duisburg"}`, "Duisburg"},
+		{"both set", `{"Location": "Duisburg", "location": "Düsseldorf"}`, "Duisburg"},
+		{"missing", `{}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Schedule
+			if err := json.Unmarshal([]byte(tt.body), &s); err != nil {
+				t.Fatal(err)
+			}
+			if s.Location != tt.want {
+				t.Errorf("location %q, want %q", s.Location, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreboardUnmarshalScores(t *testing.T) {
+	for _, body := range []string{
+		`{"statcrewID": "g1", "homeScore": "28", "awayScore": "14"}`,
+		`{"statcrewID": "g1", "homeScore": 28, "awayScore": 14}`,
+	} {
+		var s Scoreboard
+		if err := json.Unmarshal([]byte(body), &s); err != nil {
+			t.Fatalf("%s: %v", body, err)
+		}
+		if s.StatcrewID != "g1" || s.HomeScore != 28 || s.AwayScore != 14 {
+			t.Errorf("%s: %+v, want g1 28-14", body, s)
+		}
+	}
+
+	// Encoded again, the scores are numbers
+	data, err := json.Marshal(Scoreboard{StatcrewID: "g1", HomeScore: 28, AwayScore: 14})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["homeScore"].(float64); !ok {
+		t.Errorf("homeScore encoded as %T, want a number", raw["homeScore"])
+	}
+}
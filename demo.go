@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+)
+
+// demoDSN is the database of demo mode. It is in memory, so the mock season
+// never replaces the games of the configured database and is gone on exit.
+const demoDSN = "file:goelf-demo?mode=memory&cache=shared"
+
+// demoRefreshMessage answers refresh requests in demo mode
+const demoRefreshMessage = "Demo mode is active, the data is not refreshed (unset GOELF_DEMO_MODE to fetch from the upstream)"
+
+// startDemoMode replaces startDataFetcher when GOELF_DEMO_MODE is set. The
+// in-memory demo database is filled with the mock season once and no cron
+// job is started, so a demo shows the same data regardless of the network.
+func startDemoMode() {
+	logWarnf("==================================================================")
+	logWarnf("DEMO MODE (GOELF_DEMO_MODE=true): serving the mock season only,")
+	logWarnf("nothing is fetched from the upstream and refreshes are disabled")
+	logWarnf("the data lives in memory, the database file is not used")
+	logWarnf("==================================================================")
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	if err := replaceWithMockData(ctx); err != nil {
+		logErrorf("Error loading the mock season: %v", err)
+	}
+}
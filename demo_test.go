@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDemoModeLeavesDatabaseUntouched(t *testing.T) {
+	loadTestConfig(t)
+	config.DBPath = filepath.Join(t.TempDir(), "goelf.db")
+	if err := initDB(); err != nil {
+		t.Fatalf("initDB: %v", err)
+	}
+	seedGames(t, testGames)
+	db.Close()
+
+	config.DemoMode = true
+	if err := initDB(); err != nil {
+		t.Fatalf("initDB in demo mode: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		dbFile = ""
+		invalidateStandings()
+	})
+	startDemoMode()
+
+	r, err := setupRouter()
+	if err != nil {
+		t.Fatalf("setupRouter: %v", err)
+	}
+	var data ScheduleData
+	decodeResponse(t, serve(r, http.MethodGet, "/api/schedule"), &data)
+	if len(data.FinishedMatches) != 3 || len(data.UpcomingMatches) != 1 {
+		t.Errorf("%d finished and %d upcoming weeks, want the 3 played and 1 upcoming mock weeks", len(data.FinishedMatches), len(data.UpcomingMatches))
+	}
+
+	db.Close()
+	if err := openDB(sqliteDSN(config.DBPath)); err != nil {
+		t.Fatalf("reopening the database file: %v", err)
+	}
+	if got := countGames(t); got != len(testGames) {
+		t.Errorf("database file holds %d games, want the %d stored before the demo", got, len(testGames))
+	}
+}
+
+func TestMockSchedulesAreCurrent(t *testing.T) {
+	loadTestConfig(t)
+	now := time.Now()
+	for _, game := range mockSchedules(now) {
+		kickoff, ok := parseGameTime(game)
+		if !ok {
+			t.Fatalf("%s has no kickoff", game.StatcrewID)
+		}
+		if played := isPlayed(game.HomeScore, game.AwayScore); played != kickoff.Before(now) {
+			t.Errorf("%s kicks off %s, played %v", game.StatcrewID, kickoff, played)
+		}
+		if now.Sub(kickoff) > 21*24*time.Hour {
+			t.Errorf("%s kicks off %s, more than three weeks ago", game.StatcrewID, kickoff)
+		}
+	}
+}
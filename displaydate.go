@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // The runtime image has no zoneinfo
+
+	"github.com/gin-gonic/gin"
+)
+
+// displayLocation is the time zone of the DisplayDate field, loaded from
+// GOELF_TIMEZONE
+var displayLocation = time.UTC
+
+// displayLocales translate the English day and month names of a formatted
+// date. Full names come first so they are replaced before their
+// abbreviations.
+var displayLocales = map[string]*strings.Replacer{
+	"en": strings.NewReplacer(),
+	"de": strings.NewReplacer(
+		"Monday", "Montag", "Tuesday", "Dienstag", "Wednesday", "Mittwoch",
+		"Thursday", "Donnerstag", "Friday", "Freitag", "Saturday", "Samstag", "Sunday", "Sonntag",
+		"January", "Januar", "February", "Februar", "March", "März", "May", "Mai",
+		"June", "Juni", "July", "Juli", "October", "Oktober", "December", "Dezember",
+		"Mon", "Mo", "Tue", "Di", "Wed", "Mi", "Thu", "Do", "Fri", "Fr", "Sat", "Sa", "Sun", "So",
+		"Mar", "Mär", "Oct", "Okt", "Dec", "Dez",
+	),
+}
+
+// formatDisplayDate formats the kickoff of a game with the configured layout,
+// time zone and locale. Games without a parseable date return "".
+func formatDisplayDate(s Schedule) string {
+	kickoff, ok := parseGameTime(s)
+	if !ok {
+		return ""
+	}
+	formatted := kickoff.In(displayLocation).Format(config.DisplayDateLayout)
+	return displayLocales[config.DisplayLocale].Replace(formatted)
+}
+
+// wantsDisplayDates reports whether the request asked for the DisplayDate
+// field with ?format=display
+func wantsDisplayDates(c *gin.Context) (bool, error) {
+	switch format := c.Query("format"); format {
+	case "":
+		return false, nil
+	case "display":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown format %q, only display is supported", format)
+	}
+}
+
+// stripDisplayDates clears the DisplayDate of games unless the request asked
+// for it, so it is only included with ?format=display
+func stripDisplayDates(games []Schedule, display bool) {
+	if display {
+		return
+	}
+	for i := range games {
+		games[i].DisplayDate = ""
+	}
+}
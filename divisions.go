@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DivisionStrength summarizes the strength metrics of the teams in a division
+type DivisionStrength struct {
+	Rank         int
+	Division     string
+	Teams        int
+	AvgSoS       float64
+	AvgSoV       float64
+	AvgPointDiff float64
+}
+
+// computeDivisionStrength averages SoS, SoV and point differential over the
+// teams of each division. Divisions are ranked by average SoS, then average
+// point differential.
+func computeDivisionStrength(standings []DivisionData) []DivisionStrength {
+	strengths := []DivisionStrength{}
+	for _, division := range standings {
+		if len(division.Teams) == 0 {
+			continue
+		}
+
+		strength := DivisionStrength{Division: division.Division, Teams: len(division.Teams)}
+		for _, team := range division.Teams {
+			strength.AvgSoS += team.SoS
+			strength.AvgSoV += team.SoV
+			strength.AvgPointDiff += float64(team.PointDiff)
+		}
+		count := float64(len(division.Teams))
+		strength.AvgSoS /= count
+		strength.AvgSoV /= count
+		strength.AvgPointDiff /= count
+
+		strengths = append(strengths, strength)
+	}
+
+	sort.SliceStable(strengths, func(i, j int) bool {
+		if strengths[i].AvgSoS != strengths[j].AvgSoS {
+			return strengths[i].AvgSoS > strengths[j].AvgSoS
+		}
+		return strengths[i].AvgPointDiff > strengths[j].AvgPointDiff
+	})
+	for i := range strengths {
+		strengths[i].Rank = i + 1
+	}
+	return strengths
+}
+
+func getDivisionStrength(c *gin.Context) {
+	standings, err := getStandings(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, computeDivisionStrength(standings))
+}
+
+// DivisionLeader is the first placed team of a division
+type DivisionLeader struct {
+	Division  string
+	TeamName  string
+	Record    string
+	Wins      int
+	Losses    int
+	DivRecord string
+	Logo      string
+	Clinched  bool // Clinched the division
+}
+
+// divisionLeaders returns the leader of each division in display order.
+// Divisions without teams are left out.
+func divisionLeaders(standings []DivisionData) []DivisionLeader {
+	leaders := []DivisionLeader{}
+	for _, division := range standings {
+		if len(division.Teams) == 0 {
+			continue
+		}
+
+		leader := division.Teams[0]
+		leaders = append(leaders, DivisionLeader{
+			Division:  division.Division,
+			TeamName:  leader.TeamName,
+			Record:    leader.Record,
+			Wins:      leader.Wins,
+			Losses:    leader.Losses,
+			DivRecord: leader.DivRecord,
+			Logo:      leader.Logo,
+			Clinched:  leader.Clinched,
+		})
+	}
+	return leaders
+}
+
+// getDivisionLeaders returns the first placed team of each division
+func getDivisionLeaders(c *gin.Context) {
+	standings, err := getStandings(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, divisionLeaders(standings))
+}
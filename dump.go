@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// dumpResponse writes a raw upstream response to GOELF_DUMP_DIR when
+// GOELF_DUMP_RESPONSES is enabled, e.g. to reproduce parse failures offline.
+// Only the newest GOELF_DUMP_KEEP files of each kind are kept. Failures are
+// logged and never affect the fetch.
+func dumpResponse(kind string, body []byte) {
+	if !config.DumpResponses {
+		return
+	}
+
+	if err := os.MkdirAll(config.DumpDir, 0755); err != nil {
+		logErrorf("Error creating dump directory: %v", err)
+		return
+	}
+
+	// The timestamp sorts lexically, which the pruning relies on
+	name := fmt.Sprintf("%s-%s.json", kind, time.Now().UTC().Format("20060102T150405.000000000Z"))
+	path := filepath.Join(config.DumpDir, name)
+	if err := os.WriteFile(path, body, 0644); err != nil {
+		logErrorf("Error dumping %s response: %v", kind, err)
+		return
+	}
+	logDebugf("Dumped %s response (%d bytes) to %s", kind, len(body), path)
+
+	pruneDumps(kind)
+}
+
+// pruneDumps removes the oldest dumps of a kind beyond GOELF_DUMP_KEEP
+func pruneDumps(kind string) {
+	files, err := filepath.Glob(filepath.Join(config.DumpDir, kind+"-*.json"))
+	if err != nil || len(files) <= config.DumpKeep {
+		return
+	}
+
+	sort.Strings(files)
+	for _, file := range files[:len(files)-config.DumpKeep] {
+		if err := os.Remove(file); err != nil {
+			logErrorf("Error removing old dump: %v", err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Envelope wraps a response with metadata for ?envelope=true. Without it
+// the data is returned bare, as it always was.
+type Envelope struct {
+	Data interface{}  `json:"data"`
+	Meta EnvelopeMeta `json:"meta"`
+}
+
+// EnvelopeMeta describes the wrapped data
+type EnvelopeMeta struct {
+	Count       int    `json:"count"`                 // Number of games or teams in the data
+	LastUpdated string `json:"lastUpdated,omitempty"` // When a stored game of the league last changed
+}
+
+// wantsEnvelope reports whether the request asked for ?envelope=true
+func wantsEnvelope(c *gin.Context) bool {
+	return c.Query("envelope") == "true"
+}
+
+// scheduleLastUpdated returns when a game of the context's league was last
+// inserted or changed, or "" if there are no games
+func scheduleLastUpdated(ctx context.Context) (string, error) {
+	var updatedAt sql.NullString
+	err := db.QueryRowContext(ctx, "SELECT MAX(updated_at) FROM schedule WHERE league = ?", leagueFromContext(ctx)).Scan(&updatedAt)
+	if err != nil || !updatedAt.Valid {
+		return "", err
+	}
+
+	// SQLite's CURRENT_TIMESTAMP is UTC
+	if t, err := time.Parse(time.DateTime, updatedAt.String); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+	return updatedAt.String, nil
+}
+
+// respondEnvelope writes the data wrapped in an Envelope
+func respondEnvelope(c *gin.Context, data interface{}, count int) {
+	lastUpdated, err := scheduleLastUpdated(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+	respondJSON(c, http.StatusOK, Envelope{Data: data, Meta: EnvelopeMeta{Count: count, LastUpdated: lastUpdated}})
+}
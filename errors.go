@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Stable error codes returned by the API
+const (
+	codeBadRequest       = "bad_request"
+	codeUnauthorized     = "unauthorized"
+	codeForbidden        = "forbidden"
+	codeNotFound         = "not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeDBError          = "db_error"
+	codePayloadTooLarge  = "payload_too_large"
+)
+
+// APIError is the error envelope returned by all API endpoints
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type errorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// respondError aborts the request with the given status and error envelope
+func respondError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, errorResponse{Error: APIError{Code: code, Message: message}})
+}
+
+// respondDBError logs a database error and responds with a generic message,
+// so driver details never reach the client
+func respondDBError(c *gin.Context, err error) {
+	logErrorf("Database error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	respondError(c, http.StatusInternalServerError, codeDBError, "database error")
+}
+
+// isAPIPath reports whether the path belongs to the API rather than the
+// frontend
+func isAPIPath(path string) bool {
+	return path == "/api" || strings.HasPrefix(path, "/api/")
+}
+
+// apiNotFound answers unknown API paths with the JSON error envelope. Other
+// paths get gin's default 404.
+func apiNotFound(c *gin.Context) {
+	if isAPIPath(c.Request.URL.Path) {
+		respondError(c, http.StatusNotFound, codeNotFound, "no such endpoint")
+	}
+}
+
+// apiMethodNotAllowed answers requests with a method the path doesn't
+// support with 405 and the supported methods in the Allow header
+func apiMethodNotAllowed(r *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Allow", strings.Join(allowedMethods(r.Routes(), c.Request.URL.Path), ", "))
+		if isAPIPath(c.Request.URL.Path) {
+			respondError(c, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+		}
+	}
+}
+
+// allowedMethods returns the sorted methods of the routes matching the path
+func allowedMethods(routes gin.RoutesInfo, path string) []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, route := range routes {
+		if seen[route.Method] || !routeMatches(route.Path, path) {
+			continue
+		}
+		seen[route.Method] = true
+		methods = append(methods, route.Method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// routeMatches reports whether a path matches a gin route pattern with
+// :param and *wildcard segments
+func routeMatches(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, "*") {
+			return true
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if !strings.HasPrefix(part, ":") && part != pathParts[i] {
+			return false
+		}
+	}
+	return len(patternParts) == len(pathParts)
+}
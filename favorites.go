@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestFavorites returns the normalized names of the favorite teams from
+// ?favorites=TeamA,TeamB, or GOELF_FAVORITES if the parameter is missing.
+// It returns nil when there are no favorites.
+func requestFavorites(c *gin.Context) map[string]bool {
+	names := config.Favorites
+	if param, ok := c.GetQuery("favorites"); ok {
+		names = strings.Split(param, ",")
+	}
+
+	var favorites map[string]bool
+	for _, name := range names {
+		if normalized := normalizeTeamName(name); normalized != "" {
+			if favorites == nil {
+				favorites = make(map[string]bool)
+			}
+			favorites[normalized] = true
+		}
+	}
+	return favorites
+}
+
+// markFavoriteGames flags the games involving a favorite team
+func markFavoriteGames(games []Schedule, favorites map[string]bool) {
+	for i, game := range games {
+		games[i].Favorite = favorites[normalizeTeamName(game.HomeTeam)] || favorites[normalizeTeamName(game.AwayTeam)]
+	}
+}
+
+// markFavoriteStandings returns a copy of the standings with the favorite
+// teams flagged. The standings are copied because they are shared through the
+// cache.
+func markFavoriteStandings(standings []DivisionData, favorites map[string]bool) []DivisionData {
+	if favorites == nil {
+		return standings
+	}
+
+	marked := make([]DivisionData, len(standings))
+	for i, data := range standings {
+		teams := make([]TeamStanding, len(data.Teams))
+		for j, team := range data.Teams {
+			team.Favorite = favorites[normalizeTeamName(team.TeamName)]
+			teams[j] = team
+		}
+		marked[i] = DivisionData{Division: data.Division, Teams: teams}
+	}
+	return marked
+}
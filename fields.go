@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// jsonFields maps the JSON keys of a struct type to their field index.
+// Unexported fields and fields tagged json:"-" aren't encoded, so they can't
+// be selected either.
+func jsonFields(t reflect.Type) map[string]int {
+	fields := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = i
+	}
+	return fields
+}
+
+// parseFields parses a comma separated ?fields= parameter and validates each
+// field against the JSON keys of the given struct type. An empty parameter
+// returns nil, meaning all fields.
+func parseFields(param string, t reflect.Type) ([]string, error) {
+	if strings.TrimSpace(param) == "" {
+		return nil, nil
+	}
+
+	known := jsonFields(t)
+	var fields, unknown []string
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := known[field]; !ok {
+			unknown = append(unknown, field)
+			continue
+		}
+		fields = append(fields, field)
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown fields: %s", strings.Join(unknown, ", "))
+	}
+	return fields, nil
+}
+
+// selectFields returns only the requested JSON keys of a struct value
+func selectFields(v interface{}, fields []string) map[string]interface{} {
+	value := reflect.ValueOf(v)
+	known := jsonFields(value.Type())
+
+	selected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if i, ok := known[field]; ok {
+			selected[field] = value.Field(i).Interface()
+		}
+	}
+	return selected
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldsSkipsHiddenFields(t *testing.T) {
+	type row struct {
+		Name   string `json:"name"`
+		Secret string `json:"-"`
+		Plain  int
+		hidden bool
+	}
+	typ := reflect.TypeOf(row{})
+
+	fields, err := parseFields("name, Plain", typ)
+	if err != nil || len(fields) != 2 {
+		t.Fatalf("parseFields = %v, %v, want name and Plain", fields, err)
+	}
+	selected := selectFields(row{Name: "a", Secret: "s", Plain: 3, hidden: true}, fields)
+	if !reflect.DeepEqual(selected, map[string]interface{}{"name": "a", "Plain": 3}) {
+		t.Errorf("selectFields = %v", selected)
+	}
+
+	for _, param := range []string{"Secret", "-", "hidden", "name,nope"} {
+		if _, err := parseFields(param, typ); err == nil {
+			t.Errorf("parseFields(%q) accepted a field that isn't encoded", param)
+		}
+	}
+}
+
+func TestGetScheduleFields(t *testing.T) {
+	r := newTestRouter(t)
+	seedGames(t, testGames)
+
+	var data struct {
+		FinishedMatches []struct {
+			Matches []map[string]json.RawMessage
+		}
+	}
+	decodeResponse(t, serve(r, http.MethodGet, "/api/schedule?fields=homename,date"), &data)
+	if len(data.FinishedMatches) == 0 {
+		t.Fatal("no finished weeks")
+	}
+	if match := data.FinishedMatches[0].Matches[0]; len(match) != 2 || match["homename"] == nil || match["date"] == nil {
+		t.Errorf("match keys %v, want homename and date", match)
+	}
+
+	// The unexported played flag must be rejected, not panic
+	for _, query := range []string{"played", "nope"} {
+		if w := serve(r, http.MethodGet, "/api/schedule?fields="+query); w.Code != http.StatusBadRequest {
+			t.Errorf("?fields=%s status %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// cliFlag is a command-line flag overriding a GOELF_* environment variable
+type cliFlag struct {
+	name   string
+	env    string
+	usage  string
+	def    string // Shown in -help, empty for none
+	isBool bool
+	field  string // Config field the setting resolves to
+}
+
+// cliFlags are the settings that can also be passed on the command line.
+// Secrets like GOELF_ADMIN_TOKEN are left out, command lines are visible to
+// every user of the host.
+var cliFlags = []cliFlag{
+	{name: "addr", env: "GOELF_ADDR", usage: "listen `address` of the server", def: defaultAddr, field: "Addr"},
+	{name: "db", env: "GOELF_DB", usage: "SQLite database `file`, its directory is created if missing", def: defaultDBPath, field: "DBPath"},
+	{name: "cron", env: "GOELF_FETCH_CRON", usage: "cron `spec` of the fetch job while games are coming up", def: defaultFetchCron, field: "FetchCron"},
+	{name: "offseason-cron", env: "GOELF_OFFSEASON_FETCH_CRON", usage: "cron `spec` of the fetch job when no game is within the next week", def: defaultOffseasonFetchCron, field: "OffseasonFetchCron"},
+	{name: "fetch-on-start", env: "GOELF_FETCH_ON_START", usage: "fetch once right after startup", def: "true", isBool: true, field: "FetchOnStart"},
+	{name: "demo", env: "GOELF_DEMO_MODE", usage: "serve only the mock season and never fetch from the upstream", isBool: true, field: "DemoMode"},
+	{name: "mock", env: "GOELF_ENABLE_MOCK", usage: "insert mock data when the initial fetch returns no games", isBool: true, field: "EnableMock"},
+	{name: "log-level", env: "GOELF_LOG_LEVEL", usage: "least severe logged `level`: debug, info, warn or error", def: defaultLogLevel, field: "LogLevel"},
+	{name: "web-dir", env: "GOELF_WEB_DIR", usage: "serve templates/ and assets/ from this `directory` instead of the embedded copies", field: "WebDir"},
+	{name: "templates", env: "GOELF_TEMPLATES", usage: "`glob` of the HTML templates, overriding the embedded ones", field: "Templates"},
+	{name: "tls-cert", env: "GOELF_TLS_CERT", usage: "certificate `file` (PEM) to serve HTTPS, requires -tls-key", field: "TLSCert"},
+	{name: "tls-key", env: "GOELF_TLS_KEY", usage: "private key `file` (PEM) of -tls-cert", field: "TLSKey"},
+}
+
+// flagsSet are the names of the flags given on the command line
+var flagsSet = make(map[string]bool)
+
+// envFlag is the flag.Value of a cliFlag
+type envFlag struct {
+	value  string
+	isBool bool
+}
+
+func (f *envFlag) String() string { return f.value }
+
+func (f *envFlag) Set(value string) error {
+	if f.isBool {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		value = strconv.FormatBool(b)
+	}
+	f.value = value
+	return nil
+}
+
+func (f *envFlag) IsBoolFlag() bool { return f.isBool }
+
+// parseFlags parses the command line and applies the given flags as their
+// environment variables, so loadConfig stays the one place that reads and
+// validates settings. A flag takes precedence over its variable, which
+// takes precedence over the default. -help lists the flags and exits.
+func parseFlags(args []string) error {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s [flags]\n\n", fs.Name())
+		fmt.Fprintf(fs.Output(), "Flags take precedence over the environment variable in parentheses.\n\n")
+		fs.PrintDefaults()
+		printEnvSettings(fs.Output())
+	}
+	for _, f := range cliFlags {
+		fs.Var(&envFlag{value: f.def, isBool: f.isBool}, f.name, f.usage+" ("+f.env+")")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	envs := make(map[string]string)
+	for _, f := range cliFlags {
+		envs[f.name] = f.env
+	}
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		flagsSet[f.Name] = true
+		if setErr := os.Setenv(envs[f.Name], f.Value.String()); setErr != nil && err == nil {
+			err = setErr
+		}
+	})
+	return err
+}
+
+// printEnvSettings lists the GOELF_* environment variables that have no
+// flag with their defaults, see the README for their meaning
+func printEnvSettings(w io.Writer) {
+	flagged := make(map[string]bool)
+	for _, f := range cliFlags {
+		flagged[f.env] = true
+	}
+	readConfig() // Records the defaults
+
+	var keys []string
+	for key := range envDefaults {
+		if !flagged[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "\nEnvironment variables without a flag (default in parentheses):\n")
+	for _, key := range keys {
+		if def := envDefaults[key]; def != "" {
+			fmt.Fprintf(w, "  %s (%s)\n", key, def)
+		} else {
+			fmt.Fprintf(w, "  %s\n", key)
+		}
+	}
+	fmt.Fprintf(w, "  GOELF_LEAGUE_<ID>_URLS and GOELF_LEAGUE_<ID>_DIVISIONS for each further league\n")
+}
+
+// secretSettings are the Config fields that are only logged as set, never
+// with their value
+var secretSettings = map[string]bool{"AdminToken": true, "WebhookURL": true}
+
+// logEffectiveConfig logs every resolved setting. Those with a flag are
+// logged together with where their value came from.
+func logEffectiveConfig() {
+	sources := make(map[string]string)
+	for _, f := range cliFlags {
+		source := "default"
+		if flagsSet[f.name] {
+			source = "flag -" + f.name
+		} else if os.Getenv(f.env) != "" {
+			source = f.env
+		}
+		sources[f.field] = source
+	}
+
+	logInfof("Effective configuration:")
+	value := reflect.ValueOf(config)
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Name
+		setting := formatSetting(value.Field(i))
+		if secretSettings[name] && !value.Field(i).IsZero() {
+			setting = "(set)"
+		}
+		if source, ok := sources[name]; ok {
+			logInfof("  %s = %s (%s)", name, setting, source)
+		} else {
+			logInfof("  %s = %s", name, setting)
+		}
+	}
+}
+
+// formatSetting formats a Config field for the log, quoting strings so
+// empty values stay visible
+func formatSetting(v reflect.Value) string {
+	switch setting := v.Interface().(type) {
+	case string, []string:
+		return fmt.Sprintf("%q", setting)
+	case time.Duration:
+		return setting.String()
+	default:
+		return fmt.Sprintf("%+v", setting)
+	}
+}
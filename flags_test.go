@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPrintEnvSettings(t *testing.T) {
+	var out strings.Builder
+	printEnvSettings(&out)
+	help := out.String()
+
+	for _, want := range []string{
+		"  GOELF_MAX_LIMIT (200)\n",
+		"  GOELF_PLAYED_MODE (score)\n",
+		"  GOELF_GAME_DURATION (3h0m0s)\n",
+		"  GOELF_DIVISIONS (EAST,WEST,NORTH,SOUTH)\n",
+		"  GOELF_LEAGUES (elf)\n",
+		"  GOELF_ADMIN_TOKEN\n", // No default
+	} {
+		if !strings.Contains(help, want) {
+			t.Errorf("help is missing %q", strings.TrimSpace(want))
+		}
+	}
+	// Settings with a flag are listed with the flags
+	for _, f := range cliFlags {
+		if strings.Contains(help, "  "+f.env+" ") || strings.Contains(help, "  "+f.env+"\n") {
+			t.Errorf("%s is listed again although it has the flag -%s", f.env, f.name)
+		}
+	}
+}
+
+func TestLogEffectiveConfig(t *testing.T) {
+	loadTestConfig(t)
+	config.AdminToken = "secret-token"
+	config.WebhookURL = "https://hooks.example/secret"
+	logLevel = levelInfo
+	var out strings.Builder
+	log.SetOutput(&out)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		logLevel = levelError
+	})
+
+	logEffectiveConfig()
+	logged := out.String()
+	for _, want := range []string{
+		`Addr = ":7788" (default)`,
+		"MaxLimit = 200\n",
+		"GameDuration = 3h0m0s\n",
+		`Divisions = ["EAST" "WEST" "NORTH" "SOUTH"]`,
+		"AdminToken = (set)\n",
+		`ResyncCron = ""`,
+	} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("log is missing %q:\n%s", want, logged)
+		}
+	}
+	if strings.Contains(logged, "secret") {
+		t.Errorf("log contains a secret:\n%s", logged)
+	}
+}
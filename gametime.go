@@ -0,0 +1,200 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Game states reported in the Status field
+const (
+	statusScheduled = "scheduled"
+	statusLive      = "live"
+	statusFinal     = "final"
+)
+
+// gameDateLayouts are the formats tried when parsing game dates
+var gameDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05.000Z",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+}
+
+// Modes of GOELF_PLAYED_MODE deciding when a game counts as played
+const (
+	playedScore  = "score"
+	playedDate   = "date"
+	playedHybrid = "hybrid"
+)
+
+// Policies of GOELF_UNDATED_GAMES for games without a parseable date in date
+// ordered listings
+const (
+	undatedLast    = "last"
+	undatedExclude = "exclude"
+)
+
+// parseGameTime returns the kickoff time of a game, preferring game_date and
+// falling back to the date field
+func parseGameTime(s Schedule) (time.Time, bool) {
+	for _, value := range []string{s.GameDate, s.Date} {
+		if value == "" {
+			continue
+		}
+		for _, layout := range gameDateLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// gamePlayed reports whether a game counts as played in the standings and
+// results, depending on GOELF_PLAYED_MODE:
+//   - score: the game has a non-zero score. A 0-0 final is never counted and
+//     the partial score of a live game already is.
+//   - date: the kickoff plus the game duration has passed, regardless of the
+//     score. 0-0 finals count as ties, but so does a game whose result the
+//     upstream hasn't delivered yet. Games without a parseable date never
+//     count.
+//   - hybrid: a scored game counts unless it is live, a game without a score
+//     once it has ended. Games without a parseable date fall back to the
+//     score.
+func gamePlayed(s Schedule, now time.Time) bool {
+	scored := isPlayed(s.HomeScore, s.AwayScore)
+	if config.PlayedMode == playedScore {
+		return scored
+	}
+
+	kickoff, ok := parseGameTime(s)
+	if !ok {
+		return config.PlayedMode == playedHybrid && scored
+	}
+	ended := !now.Before(kickoff.Add(config.GameDuration))
+	if config.PlayedMode == playedDate {
+		return ended
+	}
+	return ended || (scored && now.Before(kickoff))
+}
+
+// nextPlayedChange returns the next time after now at which gamePlayed
+// changes for one of the games without the schedule changing, or the zero
+// time if it only changes with the schedule
+func nextPlayedChange(games []Schedule, now time.Time) time.Time {
+	if config.PlayedMode == playedScore {
+		return time.Time{}
+	}
+
+	var next time.Time
+	consider := func(t time.Time) {
+		if t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	for _, game := range games {
+		kickoff, ok := parseGameTime(game)
+		if !ok {
+			continue
+		}
+		consider(kickoff.Add(config.GameDuration))
+		if config.PlayedMode == playedHybrid && isPlayed(game.HomeScore, game.AwayScore) {
+			consider(kickoff)
+		}
+	}
+	return next
+}
+
+// gameStatus derives the state of a game from its kickoff time and scores.
+// A game is live from kickoff until the configured game duration has passed,
+// afterwards it is final once it counts as played. Games without a
+// parseable date fall back to gamePlayed alone.
+func gameStatus(s Schedule, now time.Time) string {
+	played := gamePlayed(s, now)
+
+	kickoff, ok := parseGameTime(s)
+	if !ok {
+		if played {
+			return statusFinal
+		}
+		return statusScheduled
+	}
+
+	switch {
+	case now.Before(kickoff):
+		return statusScheduled
+	case now.Before(kickoff.Add(config.GameDuration)):
+		return statusLive
+	case played:
+		return statusFinal
+	default:
+		// Past games without a result are still awaiting one
+		return statusScheduled
+	}
+}
+
+// sortGamesByDate orders games chronologically by kickoff. Games without a
+// parseable date keep their relative order and are placed last.
+func sortGamesByDate(games []Schedule) {
+	type datedGame struct {
+		game    Schedule
+		kickoff time.Time
+		ok      bool
+	}
+
+	dated := make([]datedGame, len(games))
+	for i, game := range games {
+		kickoff, ok := parseGameTime(game)
+		dated[i] = datedGame{game, kickoff, ok}
+	}
+
+	sort.SliceStable(dated, func(i, j int) bool {
+		if dated[i].ok != dated[j].ok {
+			return dated[i].ok
+		}
+		return dated[i].kickoff.Before(dated[j].kickoff)
+	})
+
+	for i := range dated {
+		games[i] = dated[i].game
+	}
+}
+
+// applyUndatedPolicy moves the games without a parseable date behind the
+// dated ones, keeping their order, or drops them with
+// GOELF_UNDATED_GAMES=exclude
+func applyUndatedPolicy(games []Schedule) []Schedule {
+	var dated, undated []Schedule
+	for _, game := range games {
+		if _, ok := parseGameTime(game); ok {
+			dated = append(dated, game)
+		} else {
+			undated = append(undated, game)
+		}
+	}
+	if config.UndatedGames == undatedExclude {
+		return dated
+	}
+	return append(dated, undated...)
+}
+
+// warnUndatedGames logs the fetched games without a parseable date, once
+// per fetch rather than on every request listing them
+func warnUndatedGames(games []Schedule) {
+	var ids []string
+	for _, game := range games {
+		if _, ok := parseGameTime(game); !ok {
+			ids = append(ids, game.StatcrewID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	action := "listed last"
+	if config.UndatedGames == undatedExclude {
+		action = "left out of date ordered listings"
+	}
+	logWarnf("%d games have no valid date and are %s: %s", len(ids), action, strings.Join(ids, ", "))
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// undatedGames mixes dated games with ones without a parseable date
+var undatedGames = []Schedule{
+	{StatcrewID: "undated-empty", GameWeek: 3, HomeTeam: "Rhein Fire", AwayTeam: "Nordic Storm"},
+	mockGame("dated-2", 2, "2024-05-25", "15:00", "Berlin Thunder", "Hamburg Sea Devils", "Berlin", 17, 10),
+	{StatcrewID: "undated-tbd", GameWeek: 3, HomeTeam: "Berlin Thunder", AwayTeam: "Hamburg Sea Devils", Date: "TBD"},
+	mockGame("dated-1", 1, "2024-05-18", "15:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 28, 14),
+}
+
+// statcrewIDs joins the IDs of the games for comparisons
+func statcrewIDs(games []Schedule) string {
+	ids := make([]string, len(games))
+	for i, game := range games {
+		ids[i] = game.StatcrewID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSortGamesByDateUndated(t *testing.T) {
+	games := append([]Schedule(nil), undatedGames...)
+	sortGamesByDate(games)
+
+	if got, want := statcrewIDs(games), "dated-1,dated-2,undated-empty,undated-tbd"; got != want {
+		t.Errorf("sorted %s, want %s", got, want)
+	}
+}
+
+func TestApplyUndatedPolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{undatedLast, "dated-2,dated-1,undated-empty,undated-tbd"},
+		{undatedExclude, "dated-2,dated-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			loadTestConfig(t)
+			config.UndatedGames = tt.policy
+
+			games := applyUndatedPolicy(append([]Schedule(nil), undatedGames...))
+			if got := statcrewIDs(games); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleUndatedGames(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   string // Upcoming week 3
+	}{
+		{undatedLast, "test-w3-1,test-w3-2,undated-empty"},
+		{undatedExclude, "test-w3-1,test-w3-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			r := newTestRouter(t)
+			config.UndatedGames = tt.policy
+			seedGames(t, append([]Schedule{undatedGames[0]}, testGames...))
+
+			var data ScheduleData
+			decodeResponse(t, serve(r, http.MethodGet, "/api/schedule"), &data)
+			if len(data.UpcomingMatches) != 1 {
+				t.Fatalf("got %d upcoming weeks, want 1", len(data.UpcomingMatches))
+			}
+			if got := statcrewIDs(data.UpcomingMatches[0].Matches); got != tt.want {
+				t.Errorf("week 3 %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGamePlayed(t *testing.T) {
+	loadTestConfig(t)
+	scored := mockGame("scored", 1, "2024-05-18", "15:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 28, 14)
+	unscored := mockGame("unscored", 1, "2024-05-18", "15:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 0, 0)
+	kickoff, _ := parseGameTime(scored)
+	end := kickoff.Add(config.GameDuration)
+
+	tests := []struct {
+		name                string
+		game                Schedule
+		now                 time.Time
+		score, date, hybrid bool
+	}{
+		{"scored before kickoff", scored, kickoff.Add(-time.Nanosecond), true, false, true},
+		{"scored at kickoff", scored, kickoff, true, false, false},
+		{"scored just before the end", scored, end.Add(-time.Nanosecond), true, false, false},
+		{"scored at the end", scored, end, true, true, true},
+		{"unscored just before the end", unscored, end.Add(-time.Nanosecond), false, false, false},
+		{"unscored at the end", unscored, end, false, true, true},
+		{"undated scored", Schedule{HomeScore: 21, AwayScore: 7}, end, true, false, true},
+		{"undated unscored", Schedule{}, end, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for mode, want := range map[string]bool{playedScore: tt.score, playedDate: tt.date, playedHybrid: tt.hybrid} {
+				config.PlayedMode = mode
+				if got := gamePlayed(tt.game, tt.now); got != want {
+					t.Errorf("%s mode: played %v, want %v", mode, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleSplitsByPlayedMode(t *testing.T) {
+	tests := []struct {
+		mode         string
+		wantFinished int // Games, the 0-0 game of week 1 counts once it has ended
+	}{
+		{playedScore, 2},
+		{playedDate, 3},
+		{playedHybrid, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			r := newTestRouter(t)
+			config.PlayedMode = tt.mode
+			games := append([]Schedule(nil), testGames[:2]...)
+			// Only the date identifies the kickoff, which the display rows
+			// reformat to DD.MM.
+			unscored := mockGame("test-w1-3", 1, "2024-05-19", "15:00", "Berlin Thunder", "Nordic Storm", "Berlin", 0, 0)
+			unscored.GameDate = ""
+			games = append(games, unscored)
+			seedGames(t, games)
+
+			var data ScheduleData
+			decodeResponse(t, serve(r, http.MethodGet, "/api/schedule"), &data)
+			finished := 0
+			for _, week := range data.FinishedMatches {
+				finished += len(week.Matches)
+			}
+			if finished != tt.wantFinished {
+				t.Errorf("%d finished games, want %d", finished, tt.wantFinished)
+			}
+
+			// The dashboard splits its reformatted rows the same way
+			var dashboard Dashboard
+			decodeResponse(t, serve(r, http.MethodGet, "/api/dashboard?results=10&upcoming=10"), &dashboard)
+			if len(dashboard.Results) != tt.wantFinished || len(dashboard.Upcoming) != len(games)-tt.wantFinished {
+				t.Errorf("dashboard %d results and %d upcoming, want %d and %d", len(dashboard.Results), len(dashboard.Upcoming), tt.wantFinished, len(games)-tt.wantFinished)
+			}
+			for _, result := range dashboard.Results {
+				if result.Winner == "unplayed" {
+					t.Errorf("dashboard result %s is unplayed", result.StatcrewID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fetchHealth tracks the outcome of the recent schedule fetches
+var fetchHealth struct {
+	sync.Mutex
+	consecutiveFailures int
+	lastError           string
+	lastErrorClass      string // fetchErrorClass of lastError, safe to show on /healthz
+	lastSource          string // Schedule URL of the last successful download
+	lastSuccess         time.Time
+	interval            time.Duration // Time between fetches on the current schedule
+}
+
+// dataAgeFactor is how many fetch intervals may pass without a successful
+// fetch before the data counts as stale, unless GOELF_MAX_DATA_AGE is set
+const dataAgeFactor = 4
+
+// recordFetchResult updates the failure counter after a fetch. A successful
+// fetch resets it.
+func recordFetchResult(err error) {
+	if err == nil {
+		fetchSuccesses.Add(1)
+	} else {
+		fetchFailures.Add(1)
+	}
+
+	fetchHealth.Lock()
+	defer fetchHealth.Unlock()
+
+	if err == nil {
+		fetchHealth.consecutiveFailures = 0
+		fetchHealth.lastError = ""
+		fetchHealth.lastErrorClass = ""
+		fetchHealth.lastSuccess = time.Now()
+		return
+	}
+	fetchHealth.consecutiveFailures++
+	fetchHealth.lastError = err.Error()
+	fetchHealth.lastErrorClass = fetchErrorClass(err)
+}
+
+// fetchErrorClass describes a fetch error without its details, such as
+// upstream URLs or file paths: the upstream status code or the kind of
+// failure
+func fetchErrorClass(err error) string {
+	var statusErr httpStatusError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("upstream returned HTTP %d", statusErr.code)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "upstream timeout"
+	case errors.Is(err, errSourceUnavailable):
+		return "upstream unreachable"
+	case errors.Is(err, errResponseTooLarge):
+		return "response too large"
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return "invalid response"
+	default:
+		return "internal error"
+	}
+}
+
+// recordFetchSource remembers which schedule source answered last
+func recordFetchSource(source string) {
+	fetchHealth.Lock()
+	fetchHealth.lastSource = source
+	fetchHealth.Unlock()
+}
+
+// recordFetchInterval remembers the interval of the current fetch schedule
+func recordFetchInterval(interval time.Duration) {
+	fetchHealth.Lock()
+	fetchHealth.interval = interval
+	fetchHealth.Unlock()
+}
+
+// maxDataAge returns GOELF_MAX_DATA_AGE, or a multiple of the current fetch
+// interval if it isn't set. Zero means the age isn't checked.
+func maxDataAge(interval time.Duration) time.Duration {
+	if config.MaxDataAge > 0 {
+		return config.MaxDataAge
+	}
+	return dataAgeFactor * interval
+}
+
+// ping answers liveness probes without any I/O. Readiness, including the
+// database, is reported by /healthz.
+func ping(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"pong": true})
+}
+
+// healthz reports whether the database is reachable and whether the
+// upstream fetches are failing. Repeated fetch failures only degrade the
+// status, the service keeps serving the stored data. Of the last fetch error
+// only its class is shown, the error itself is only reported by the admin
+// endpoint healthErrors.
+func healthz(c *gin.Context) {
+	if err := databaseError(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "database": "unreachable"})
+		return
+	}
+
+	fetchHealth.Lock()
+	failures := fetchHealth.consecutiveFailures
+	lastErrorClass := fetchHealth.lastErrorClass
+	lastSource := fetchHealth.lastSource
+	lastSuccess := fetchHealth.lastSuccess
+	interval := fetchHealth.interval
+	fetchHealth.Unlock()
+
+	// Before the first successful fetch the data is as old as the process
+	// at least
+	var lastFetch interface{}
+	since := startTime
+	if !lastSuccess.IsZero() {
+		lastFetch = lastSuccess.UTC().Format(time.RFC3339)
+		since = lastSuccess
+	}
+	dataAge := time.Since(since)
+	data := "fresh"
+	if limit := maxDataAge(interval); limit > 0 && dataAge > limit {
+		data = "stale"
+	}
+
+	status := "ok"
+	if failures >= config.HealthFailureThreshold {
+		status = "degraded"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":              status,
+		"database":            "ok",
+		"consecutiveFailures": failures,
+		"lastError":           lastErrorClass,
+		"scheduleSource":      lastSource,
+		"data":                data,
+		"lastFetch":           lastFetch,
+		"dataAge":             dataAge.Round(time.Second).String(),
+		"dataAgeSeconds":      int64(dataAge.Seconds()),
+	})
+}
+
+// healthErrors reports the errors behind a degraded or unavailable /healthz.
+// They may contain file paths and upstream URLs, so unlike /healthz this is
+// an admin endpoint.
+func healthErrors(c *gin.Context) {
+	var dbError string
+	if err := databaseError(c.Request.Context()); err != nil {
+		dbError = err.Error()
+	}
+
+	fetchHealth.Lock()
+	lastError := fetchHealth.lastError
+	fetchHealth.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{"databaseError": dbError, "lastError": lastError})
+}
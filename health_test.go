@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHealthzHidesErrors(t *testing.T) {
+	r := newTestRouter(t)
+	config.AdminToken = "secret"
+	t.Cleanup(func() {
+		recordFetchResult(nil)
+		dbHealth.Lock()
+		dbHealth.checked, dbHealth.err = false, nil
+		dbHealth.Unlock()
+	})
+
+	recordFetchResult(fmt.Errorf("https://upstream.example/schedule?key=abc: %w: %w", errSourceUnavailable, httpStatusError{503}))
+	w := serve(r, http.MethodGet, "/healthz")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "upstream.example") {
+		t.Errorf("status %d, body %s, want 200 without the fetch error", w.Code, w.Body)
+	}
+	var health map[string]interface{}
+	decodeResponse(t, w, &health)
+	if health["lastError"] != "upstream returned HTTP 503" {
+		t.Errorf("lastError %v, want the upstream status", health["lastError"])
+	}
+
+	recordDBCheck(errors.New("database file: stat /var/lib/goelf/goelf.db: no such file or directory"))
+	w = serve(r, http.MethodGet, "/healthz")
+	if w.Code != http.StatusServiceUnavailable || strings.Contains(w.Body.String(), "/var/lib/goelf") {
+		t.Errorf("status %d, body %s, want 503 without the database error", w.Code, w.Body)
+	}
+
+	// The errors are only shown to an admin
+	if w := serve(r, http.MethodGet, "/api/health/errors"); w.Code != http.StatusUnauthorized {
+		t.Errorf("status %d without a token, want %d", w.Code, http.StatusUnauthorized)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/health/errors", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var details map[string]string
+	decodeResponse(t, w, &details)
+	if !strings.Contains(details["lastError"], "upstream.example") || !strings.Contains(details["databaseError"], "/var/lib/goelf") {
+		t.Errorf("details %v, want both errors", details)
+	}
+}
+
+func TestFetchErrorClass(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("https://a.example: schedule API returned %w", httpStatusError{404}), "upstream returned HTTP 404"},
+		{fmt.Errorf("https://a.example: %w: %w", errSourceUnavailable, context.DeadlineExceeded), "upstream timeout"},
+		{fmt.Errorf("https://a.example: %w: %w", errSourceUnavailable, errors.New("dial tcp: connection refused")), "upstream unreachable"},
+		{fmt.Errorf("reading response: %w of 10 bytes", errResponseTooLarge), "response too large"},
+		{fmt.Errorf("parsing JSON: %w", syntaxErr), "invalid response"},
+		{errors.New("database file: stat /var/lib/goelf/goelf.db: no such file"), "internal error"},
+	}
+
+	for _, tt := range tests {
+		if got := fetchErrorClass(tt.err); got != tt.want {
+			t.Errorf("fetchErrorClass(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
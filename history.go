@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScoreChange is a recorded change of a game's score
+type ScoreChange struct {
+	OldHomeScore int    `json:"oldHomeScore"`
+	OldAwayScore int    `json:"oldAwayScore"`
+	NewHomeScore int    `json:"newHomeScore"`
+	NewAwayScore int    `json:"newAwayScore"`
+	ChangedAt    string `json:"changedAt"`
+}
+
+// recordScoreChange adds a score_history row within the store transaction
+func recordScoreChange(ctx context.Context, tx *sql.Tx, id string, previous, score [2]int) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO score_history (statcrew_id, old_home_score, old_away_score, new_home_score, new_away_score) VALUES (?, ?, ?, ?, ?)",
+		id, previous[0], previous[1], score[0], score[1])
+	if err != nil {
+		return fmt.Errorf("recording score change of %s: %w", id, err)
+	}
+	return nil
+}
+
+// getGameHistory returns the recorded score changes of a game, oldest first.
+// It is empty unless GOELF_SCORE_HISTORY is enabled.
+func getGameHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schedule WHERE statcrew_id = ?)", id).Scan(&exists); err != nil {
+		respondDBError(c, err)
+		return
+	}
+	if !exists {
+		respondError(c, http.StatusNotFound, codeNotFound, "game not found")
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT old_home_score, old_away_score, new_home_score, new_away_score, changed_at FROM score_history WHERE statcrew_id = ? ORDER BY id", id)
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+	defer rows.Close()
+
+	history := []ScoreChange{}
+	for rows.Next() {
+		var change ScoreChange
+		if err := rows.Scan(&change.OldHomeScore, &change.OldAwayScore, &change.NewHomeScore, &change.NewAwayScore, &change.ChangedAt); err != nil {
+			respondDBError(c, err)
+			return
+		}
+		history = append(history, change)
+	}
+	if err := rows.Err(); err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importSchedule upserts a JSON array of games from the request body, e.g.
+// fixtures for testing. Invalid entries are skipped. Unlike a fetch, games
+// missing from the payload are kept. Bodies larger than
+// GOELF_MAX_REQUEST_BYTES are rejected by limitRequestBody.
+func importSchedule(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondError(c, http.StatusRequestEntityTooLarge, codePayloadTooLarge, fmt.Sprintf("body exceeds the limit of %d bytes", tooLarge.Limit))
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeBadRequest, "reading body: "+err.Error())
+		return
+	}
+
+	var schedules []Schedule
+	if err := json.Unmarshal(body, &schedules); err != nil {
+		respondError(c, http.StatusBadRequest, codeBadRequest, "body must be a JSON array of games: "+err.Error())
+		return
+	}
+	received := len(schedules)
+
+	normalizeSchedules(schedules, leagueFromContext(c.Request.Context()))
+	schedules, skipped := filterValidSchedules(schedules)
+	counts, err := storeSchedules(c.Request.Context(), schedules, false)
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	// The stored schedule no longer matches the upstream's, so the next fetch
+	// must not be skipped as unchanged
+	if err := clearFetchValidators(c.Request.Context()); err != nil {
+		logErrorf("Error clearing fetch validators: %v", err)
+	}
+
+	logInfof("Imported %d games (%d skipped)", len(schedules), skipped)
+	c.JSON(http.StatusOK, gin.H{
+		"received":  received,
+		"skipped":   skipped,
+		"inserted":  counts.Inserted,
+		"updated":   counts.Updated,
+		"unchanged": counts.Unchanged,
+	})
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// League is a competition tracked by the instance. The first league of
+// GOELF_LEAGUES is the primary one, served when a request has no ?league=.
+type League struct {
+	ID        string   // Value of ?league= and of the schedule's league column
+	URLs      []string // Schedule sources tried in order
+	Divisions string   // JSON file mapping the league's teams to divisions, optional
+}
+
+// leagueDivisions maps a league ID to the team divisions of its Divisions
+// file. Leagues without a file use the built-in teamDivisions.
+var leagueDivisions = map[string]map[string]string{}
+
+// leagueIDPattern limits league IDs to what is safe in env variable names
+var leagueIDPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// loadLeagues resolves GOELF_LEAGUES. The primary league fetches from
+// GOELF_SCHEDULE_URLS, every other league from GOELF_LEAGUE_<ID>_URLS. It
+// also returns the division maps of the leagues with a Divisions file.
+func loadLeagues() ([]League, map[string]map[string]string, error) {
+	var leagues []League
+	divisions := make(map[string]map[string]string)
+	seen := make(map[string]bool)
+	for i, id := range config.LeagueIDs {
+		id = strings.ToLower(id)
+		if !leagueIDPattern.MatchString(id) {
+			return nil, nil, fmt.Errorf("GOELF_LEAGUES: invalid league %q, use lowercase letters, digits and _", id)
+		}
+		if seen[id] {
+			return nil, nil, fmt.Errorf("GOELF_LEAGUES: league %q is listed twice", id)
+		}
+		seen[id] = true
+
+		prefix := "GOELF_LEAGUE_" + strings.ToUpper(id)
+		league := League{ID: id, URLs: config.ScheduleURLs, Divisions: os.Getenv(prefix + "_DIVISIONS")}
+		if i > 0 {
+			league.URLs = envList(prefix+"_URLS", nil)
+			if len(league.URLs) == 0 {
+				return nil, nil, fmt.Errorf("%s_URLS is required for league %q", prefix, id)
+			}
+			for _, source := range league.URLs {
+				if !validSourceURL(source) {
+					return nil, nil, fmt.Errorf("%s_URLS contains an invalid URL %q", prefix, source)
+				}
+			}
+		}
+		if league.Divisions != "" {
+			leagueMap, err := loadLeagueDivisions(league.Divisions)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s_DIVISIONS: %w", prefix, err)
+			}
+			divisions[id] = leagueMap
+		}
+		leagues = append(leagues, league)
+	}
+	return leagues, divisions, nil
+}
+
+// loadLeagueDivisions reads a JSON object of team name to division, e.g.
+// {"Rhein Fire U19": "WEST"}, into a league's own division map. A team
+// playing in several leagues may have a different division in each.
+func loadLeagueDivisions(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var divisions map[string]string
+	if err := json.Unmarshal(data, &divisions); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for team, division := range divisions {
+		if division == "" {
+			return nil, fmt.Errorf("team %q has no division", team)
+		}
+		divisions[team] = strings.ToUpper(division)
+	}
+	return divisions, nil
+}
+
+// divisionMap returns the team to division map of a league
+func divisionMap(league string) map[string]string {
+	if divisions, ok := leagueDivisions[league]; ok {
+		return divisions
+	}
+	return teamDivisions
+}
+
+// divisionOrder returns the display order of the divisions of a map: the
+// GOELF_DIVISIONS order, followed by the map's other divisions by name
+func divisionOrder(divisions map[string]string) []string {
+	order := append([]string(nil), config.Divisions...)
+	var added []string
+	seen := make(map[string]bool)
+	for _, division := range divisions {
+		if !isKnownDivision(division) && !seen[division] {
+			seen[division] = true
+			added = append(added, division)
+		}
+	}
+	sort.Strings(added)
+	return append(order, added...)
+}
+
+// hasDivision reports whether the (upper case) division is one of the
+// GOELF_DIVISIONS or of the division map
+func hasDivision(divisions map[string]string, division string) bool {
+	for _, d := range divisionOrder(divisions) {
+		if d == division {
+			return true
+		}
+	}
+	return false
+}
+
+// primaryLeague returns the ID of the default league
+func primaryLeague() string {
+	return config.Leagues[0].ID
+}
+
+// findLeague looks up a configured league by ID
+func findLeague(id string) (League, bool) {
+	for _, league := range config.Leagues {
+		if league.ID == id {
+			return league, true
+		}
+	}
+	return League{}, false
+}
+
+type leagueContextKey struct{}
+
+// withLeague returns a context whose schedule queries only see the league's
+// games
+func withLeague(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, leagueContextKey{}, id)
+}
+
+// leagueFromContext returns the league selected with withLeague, or the
+// primary league
+func leagueFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(leagueContextKey{}).(string); ok {
+		return id
+	}
+	return primaryLeague()
+}
+
+// leagueMetaKey scopes a metadata key to a league. The primary league keeps
+// the plain key, so existing databases keep their validators.
+func leagueMetaKey(key, league string) string {
+	if league == primaryLeague() {
+		return key
+	}
+	return key + ":" + league
+}
+
+// selectLeague resolves ?league= and stores the league in the request
+// context. Unknown leagues are rejected.
+func selectLeague() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.ToLower(strings.TrimSpace(c.Query("league")))
+		if id == "" {
+			c.Next()
+			return
+		}
+		if _, ok := findLeague(id); !ok {
+			respondError(c, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("unknown league %q", id))
+			return
+		}
+
+		c.Request = c.Request.WithContext(withLeague(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// LeagueInfo describes a configured league
+type LeagueInfo struct {
+	ID      string
+	Primary bool
+	Games   int
+}
+
+// getLeagues lists the configured leagues with their number of stored games
+func getLeagues(c *gin.Context) {
+	leagues := make([]LeagueInfo, 0, len(config.Leagues))
+	for i, league := range config.Leagues {
+		info := LeagueInfo{ID: league.ID, Primary: i == 0}
+		err := db.QueryRowContext(c.Request.Context(), "SELECT COUNT(*) FROM schedule WHERE league = ?", league.ID).Scan(&info.Games)
+		if err != nil {
+			respondDBError(c, err)
+			return
+		}
+		leagues = append(leagues, info)
+	}
+	c.JSON(http.StatusOK, leagues)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadLeaguesKeepsDivisionMapsApart(t *testing.T) {
+	dir := t.TempDir()
+	youth := filepath.Join(dir, "youth.json")
+	if err := os.WriteFile(youth, []byte(`{"Rhein Fire": "u19 west", "Nordic Storm": "u19 west"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	women := filepath.Join(dir, "women.json")
+	if err := os.WriteFile(women, []byte(`{"Rhein Fire": "central"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOELF_LEAGUES", "elf,youth,women")
+	t.Setenv("GOELF_LEAGUE_YOUTH_URLS", "https://example.com/youth")
+	t.Setenv("GOELF_LEAGUE_YOUTH_DIVISIONS", youth)
+	t.Setenv("GOELF_LEAGUE_WOMEN_URLS", "https://example.com/women")
+	t.Setenv("GOELF_LEAGUE_WOMEN_DIVISIONS", women)
+	loadTestConfig(t)
+
+	tests := []struct {
+		league string
+		want   string
+	}{
+		{"elf", "NORTH"},
+		{"youth", "U19 WEST"},
+		{"women", "CENTRAL"},
+	}
+	for _, tt := range tests {
+		if got := divisionMap(tt.league)["Rhein Fire"]; got != tt.want {
+			t.Errorf("Rhein Fire is in %q in %s, want %q", got, tt.league, tt.want)
+		}
+	}
+	if _, ok := divisionMap("women")["Nordic Storm"]; ok {
+		t.Error("the youth league's teams leaked into the women's league")
+	}
+	if isKnownDivision("U19 WEST") || isKnownDivision("CENTRAL") {
+		t.Errorf("league divisions were added to GOELF_DIVISIONS: %v", config.Divisions)
+	}
+}
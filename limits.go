@@ -0,0 +1,21 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// clampLimit caps a requested number of results at the endpoint's maximum and
+// the server-wide GOELF_MAX_LIMIT, whichever is lower. A clamped limit is
+// reported in the X-Limit-Clamped header instead of failing the request.
+func clampLimit(c *gin.Context, limit, max int) int {
+	if config.MaxLimit < max {
+		max = config.MaxLimit
+	}
+	if limit <= max {
+		return limit
+	}
+	c.Header("X-Limit-Clamped", strconv.Itoa(max))
+	return max
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClampLimit(t *testing.T) {
+	loadTestConfig(t)
+	config.MaxLimit = 200
+
+	tests := []struct {
+		name        string
+		limit, max  int
+		want        int
+		wantClamped string
+	}{
+		{"below both", 10, 500, 10, ""},
+		{"endpoint maximum", 10000, 50, 50, "50"},
+		{"server-wide cap", 10000, 500, 200, "200"},
+		{"at the cap", 200, 500, 200, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			if got := clampLimit(c, tt.limit, tt.max); got != tt.want {
+				t.Errorf("clampLimit(%d, %d) = %d, want %d", tt.limit, tt.max, got, tt.want)
+			}
+			if got := w.Header().Get("X-Limit-Clamped"); got != tt.wantClamped {
+				t.Errorf("X-Limit-Clamped %q, want %q", got, tt.wantClamped)
+			}
+		})
+	}
+}
+
+func TestSearchTeamsLimitClamped(t *testing.T) {
+	r := newTestRouter(t)
+	config.MaxLimit = 3
+	seedGames(t, testGames)
+
+	w := serve(r, http.MethodGet, "/api/search/teams?q=e&limit=10000")
+	if got := w.Header().Get("X-Limit-Clamped"); got != strconv.Itoa(config.MaxLimit) {
+		t.Errorf("X-Limit-Clamped %q, want %d", got, config.MaxLimit)
+	}
+	var results []map[string]interface{}
+	decodeResponse(t, w, &results)
+	if len(results) > config.MaxLimit {
+		t.Errorf("got %d results, want at most %d", len(results), config.MaxLimit)
+	}
+}
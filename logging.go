@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log"
+)
+
+// Log levels of GOELF_LOG_LEVEL, in increasing severity
+const (
+	levelDebug = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+// logLevels maps the GOELF_LOG_LEVEL values to their level
+var logLevels = map[string]int{
+	"debug": levelDebug,
+	"info":  levelInfo,
+	"warn":  levelWarn,
+	"error": levelError,
+}
+
+// logLevel is the least severe level that is logged
+var logLevel = levelInfo
+
+// logf logs the message with the level's prefix if the level is enabled
+func logf(level int, prefix, format string, args ...interface{}) {
+	if level < logLevel {
+		return
+	}
+	log.Printf(prefix+format, args...)
+}
+
+// logDebugf logs details only needed to debug the upstream, e.g. raw
+// responses
+func logDebugf(format string, args ...interface{}) {
+	logf(levelDebug, "DEBUG ", format, args...)
+}
+
+// logInfof logs the regular operation, e.g. fetches and startup settings
+func logInfof(format string, args ...interface{}) {
+	logf(levelInfo, "INFO ", format, args...)
+}
+
+// logWarnf logs problems the service works around, e.g. invalid entries
+func logWarnf(format string, args ...interface{}) {
+	logf(levelWarn, "WARN ", format, args...)
+}
+
+// logErrorf logs failed operations
+func logErrorf(format string, args ...interface{}) {
+	logf(levelError, "ERROR ", format, args...)
+}
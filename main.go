@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"html/template"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -32,6 +37,25 @@ type Schedule struct {
 	GameDate   string `json:"gamedate"`
 	HomeLogo   string // Home team logo
 	AwayLogo   string // Away team logo
+	Status     string `json:"status"`             // scheduled, live or final (computed, not stored)
+	UpdatedAt  string `json:"updatedAt"`          // When the stored game last changed
+	Favorite   bool   `json:"favorite,omitempty"` // Involves one of the requested favorite teams
+
+	DisplayDate string `json:"displayDate,omitempty"` // Formatted kickoff, only with ?format=display
+
+	played bool // gamePlayed, set by queryDisplaySchedule before the dates are reformatted
+}
+
+// scheduleColumns are the stored columns read by scanSchedule
+const scheduleColumns = "statcrew_id, home_team, away_team, date, time, game_week, location, home_score, away_score, slug, game_date, updated_at"
+
+// scanSchedule reads a game selected with scheduleColumns
+func scanSchedule(row interface{ Scan(...interface{}) error }) (Schedule, error) {
+	var s Schedule
+	var updatedAt sql.NullString
+	err := row.Scan(&s.StatcrewID, &s.HomeTeam, &s.AwayTeam, &s.Date, &s.Time, &s.GameWeek, &s.Location, &s.HomeScore, &s.AwayScore, &s.Slug, &s.GameDate, &updatedAt)
+	s.UpdatedAt = updatedAt.String
+	return s, err
 }
 
 type GameWeek struct {
@@ -44,50 +68,112 @@ type ScheduleData struct {
 	UpcomingMatches []GameWeek
 }
 
+// Scoreboard is an upstream scoreboard entry. The upstream sends the scores
+// as strings, they are decoded to integers like the schedule's.
 type Scoreboard struct {
 	StatcrewID string `json:"statcrewID"`
-	HomeScore  string `json:"homeScore"`
-	AwayScore  string `json:"awayScore"`
+	HomeScore  int    `json:"homeScore"`
+	AwayScore  int    `json:"awayScore"`
 	HomeRecord string `json:"homeRecord"`
 	AwayRecord string `json:"awayRecord"`
 }
 
 var db *sql.DB
 
+// fetchTimeout bounds a single background fetch including its database writes
+const fetchTimeout = 2 * time.Minute
+
 func main() {
-	// Initialize database
-	initDB()
+	// Log to stdout so container runtimes collect everything in one stream
+	log.SetOutput(os.Stdout)
+	gin.DefaultErrorWriter = os.Stdout
 
-	// Start background job to fetch data
-	startDataFetcher()
+	// Load configuration from the flags and the environment
+	if err := parseFlags(os.Args[1:]); err != nil {
+		log.Fatalf("Invalid flags: %v", err)
+	}
+	if err := loadConfig(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	// Request logs are info messages
+	if logLevel > levelInfo {
+		gin.DefaultWriter = io.Discard
+	}
+	logEffectiveConfig()
+	logInfof("Upstream requests require TLS %s or newer", config.TLSMinVersion)
 
-	// Setup Gin router
-	r := gin.Default()
+	// Initialize database, the server can't run without it
+	if err := initDB(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Keep checking the database in the background for /healthz
+	startDBCheck()
+
+	// Team metadata from the database, the built-in maps are enough to run
+	if err := loadTeamMetadata(context.Background()); err != nil {
+		logErrorf("Error loading team metadata, using the built-in teams: %v", err)
+	}
+
+	// Start background job to fetch data, demos only serve the mock data
+	if config.DemoMode {
+		startDemoMode()
+	} else {
+		startDataFetcher()
+	}
+
+	r, err := setupRouter()
+	if err != nil {
+		log.Fatalf("Failed to set up the router: %v", err)
+	}
+
+	// Start server
+	scheme := "HTTP"
+	if config.TLSCert != "" {
+		scheme = "HTTPS"
+	}
+	logInfof("Server %s (%s) starting on %s (%s)", version, commit, config.Addr, scheme)
+	if err := runServer(config.Addr, r); err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+}
+
+// setupRouter creates the router with all frontend, API and health routes.
+// It only needs an open database, so handlers can be exercised through
+// httptest against any database opened with openDB.
+func setupRouter() (*gin.Engine, error) {
+	// Like gin.Default, but liveness probes are too frequent to log
+	r := gin.New()
+	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{SkipPaths: []string{"/api/ping", "/api/" + apiVersion + "/ping"}}), gin.Recovery())
+	r.Use(countRequests())
+	r.Use(compressResponses())
+
+	// Only take the client IP from X-Forwarded-For when the request comes
+	// through one of the configured proxies
+	if err := r.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid GOELF_TRUSTED_PROXIES: %w", err)
+	}
 
 	// Serve static files (for HTMX frontend)
 	r.Static("/static", "./static")
-	// Serve assets (logos)
-	r.Static("/assets", "./assets")
-	// Add custom template functions
-	r.SetFuncMap(template.FuncMap{
-		"add": func(a, b int) int {
-			return a + b
-		},
-	})
-	r.LoadHTMLGlob("templates/*")
+	// Serve assets (logos) and load the HTML templates
+	setupFrontend(r)
 
-	// API routes
-	api := r.Group("/api")
-	{
-		api.GET("/schedule", getSchedule)
-		api.GET("/scoreboard", getScoreboard)
-		api.GET("/playoffs", getPlayoffs)
-		api.GET("/refresh", refreshData)
-		api.GET("/mock", insertMockDataHandler)
-	}
+	// API routes, /api is kept as an alias of the current version
+	registerAPIRoutes(r.Group("/api/" + apiVersion))
+	registerAPIRoutes(r.Group("/api"))
+
+	// Unknown API paths and methods get JSON errors instead of plain text
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(apiNotFound)
+	r.NoMethod(apiMethodNotAllowed(r))
 
 	// Frontend routes
 	r.GET("/", func(c *gin.Context) {
+		if !htmlEnabled {
+			c.JSON(http.StatusOK, gin.H{"title": "European League Football", "message": "HTML rendering is disabled, use the /api endpoints"})
+			return
+		}
 		c.HTML(http.StatusOK, "index.html", gin.H{
 			"title": "European League Football",
 		})
@@ -95,70 +181,159 @@ func main() {
 
 	r.HEAD("/", func(c *gin.Context) { c.Status(http.StatusOK) })
 
-	// Start server
-	log.Println("Server starting on :7788")
-	log.Fatal(r.Run(":7788"))
+	// Health check
+	r.GET("/healthz", healthz)
+
+	return r, nil
 }
 
-func initDB() {
+// registerAPIRoutes adds the API endpoints to the group
+func registerAPIRoutes(api *gin.RouterGroup) {
+	if config.ServerTiming {
+		api.Use(serverTiming())
+	}
+	api.Use(limitRequestBody())
+	api.Use(selectLeague())
+	api.GET("/ping", ping)
+	api.GET("/leagues", getLeagues)
+	api.GET("/version", getVersion)
+	api.GET("/stats", getStats)
+	api.GET("/openapi.json", getOpenAPI)
+	api.GET("/schedule", getSchedule)
+	api.GET("/schedule/week/:n", getScheduleWeek)
+	api.GET("/schedule/range", getScheduleRange)
+	api.GET("/schedule/stream", getScheduleStream)
+	api.GET("/currentweek", getCurrentWeek)
+	api.GET("/schedule.ics", getScheduleCalendar)
+	api.GET("/schedule.json", downloadSchedule)
+	api.GET("/schedule.csv", getScheduleCSV)
+	api.GET("/game/:id", getGame)
+	api.GET("/game/:id/boxscore", getGameBoxScore)
+	api.GET("/game/:id/history", getGameHistory)
+	api.GET("/scoreboard", getScoreboard)
+	api.GET("/dashboard", getDashboard)
+	api.GET("/divisions/strength", getDivisionStrength)
+	api.GET("/divisions/leaders", getDivisionLeaders)
+	api.GET("/playoffs", getPlayoffs)
+	api.GET("/superlatives", getSuperlatives)
+	api.GET("/team/:name", getTeam)
+	api.GET("/team/:name/sos", getTeamSoS)
+	api.GET("/team/:name/scenarios", getTeamScenarios)
+	api.GET("/team/:name/remaining", getTeamRemaining)
+	api.GET("/search/teams", searchTeams)
+	api.GET("/teams", getTeams)
+	api.GET("/compare", compareTeams)
+	api.GET("/matrix", getMatrix)
+	api.GET("/teams/unmapped", getUnmappedTeams)
+	api.GET("/quality/missing-scores", getMissingScores)
+	api.GET("/aliases", getAliases)
+	api.GET("/refresh", refreshHandler(refreshAll))
+	api.GET("/refresh/schedule", refreshHandler(refreshSchedule))
+	api.GET("/refresh/scoreboard", refreshHandler(refreshScoreboard))
+	api.GET("/refresh/all", refreshHandler(refreshAll))
+	api.GET("/mock", insertMockDataHandler)
+	api.DELETE("/data", requireAdmin(), clearDataHandler)
+	api.POST("/standings/recompute", requireAdmin(), recomputeStandings)
+	api.POST("/schedule/import", requireAdmin(), importSchedule)
+	api.GET("/health/errors", requireAdmin(), healthErrors)
+}
+
+// sqliteDSN builds the connection string of the database file with the
+// pragmas from the GOELF_DB_* settings
+func sqliteDSN(path string) string {
+	params := url.Values{}
+	params.Set("mode", "rw")
+	params.Set("_journal_mode", config.DBJournalMode)
+	params.Set("_synchronous", config.DBSynchronous)
+	params.Set("_busy_timeout", strconv.FormatInt(config.DBBusyTimeout.Milliseconds(), 10))
+	params.Set("_cache_size", strconv.Itoa(config.DBCacheSize))
+	params.Set("_foreign_keys", strconv.FormatBool(config.DBForeignKeys))
+	return "file:" + path + "?" + params.Encode()
+}
+
+// initDB opens the database, creating the file and tables if needed. Demo
+// mode opens an in-memory database instead.
+func initDB() error {
+	// Demos never touch the configured database, the mock season would
+	// replace the stored games
+	if config.DemoMode {
+		logInfof("Demo mode uses an in-memory database, %s is left untouched", config.DBPath)
+		return openDB(demoDSN)
+	}
+
+	dbPath := config.DBPath
+
 	// Ensure database directory exists
-	dbDir := "./database"
+	dbDir := filepath.Dir(dbPath)
 	if _, err := os.Stat(dbDir); os.IsNotExist(err) {
-		log.Println("Database directory does not exist, creating it...")
+		logInfof("Database directory does not exist, creating it...")
 		if err := os.MkdirAll(dbDir, 0755); err != nil {
-			log.Fatalf("Failed to create database directory: %v", err)
+			return fmt.Errorf("creating database directory: %w", err)
 		}
-		log.Println("Database directory created successfully")
+		logInfof("Database directory created successfully")
 	}
 
-	// Database file path
-	dbPath := filepath.Join(dbDir, "elf25.db")
-
 	// Check if database file exists and has write permissions
 	fileExists := false
 	if fileInfo, err := os.Stat(dbPath); err == nil {
 		fileExists = true
 		// Check if file is read-only
 		if fileInfo.Mode().Perm()&0200 == 0 {
-			log.Println("Database file exists but is read-only, attempting to make it writable...")
-			// Try to make the file writable
+			logInfof("Database file exists but is read-only, attempting to make it writable...")
+			// Try to make the file writable. If that fails, opening the
+			// database decides whether we can continue.
 			if err := os.Chmod(dbPath, 0666); err != nil {
-				log.Fatalf("Failed to make database file writable: %v. Please run 'chmod 666 %s' manually.", err, dbPath)
+				logErrorf("Failed to make database file writable: %v. Please run 'chmod 666 %s' manually.", err, dbPath)
+			} else {
+				logInfof("Successfully made database file writable")
 			}
-			log.Println("Successfully made database file writable")
 		}
 	}
 
 	// If file doesn't exist, create it with proper permissions
 	if !fileExists {
-		log.Println("Database file does not exist, creating it...")
+		logInfof("Database file does not exist, creating it...")
 		file, err := os.OpenFile(dbPath, os.O_CREATE|os.O_RDWR, 0666)
 		if err != nil {
-			log.Fatalf("Failed to create database file: %v", err)
+			return fmt.Errorf("creating database file: %w", err)
 		}
 		file.Close()
-		log.Println("Database file created successfully with write permissions")
+		logInfof("Database file created successfully with write permissions")
 	}
 
-	// Open database with explicit read-write mode
+	// Open database with explicit read-write mode and the configured pragmas
+	dbFile = dbPath
+	dsn := sqliteDSN(dbPath)
+	logInfof("Opening database %s", dsn)
+	return openDB(dsn)
+}
+
+// openDB connects to the SQLite database at the DSN and creates missing
+// tables. Besides the database file this also accepts an in-memory database
+// like "file::memory:?cache=shared".
+func openDB(dsn string) error {
 	var err error
-	db, err = sql.Open("sqlite3", dbPath+"?mode=rw")
+	db, err = sql.Open("sqlite3", dsn)
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return fmt.Errorf("opening database: %w", err)
 	}
 
 	// Verify database connection
 	if err = db.Ping(); err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		return fmt.Errorf("connecting to database: %w", err)
 	}
 
-	log.Println("Database connection established successfully")
+	logInfof("Database connection established successfully")
 
 	// Create tables
-	createTables()
+	if err := createTables(); err != nil {
+		return fmt.Errorf("creating tables: %w", err)
+	}
+	return nil
 }
 
-func createTables() {
+// createTables creates missing tables and columns
+func createTables() error {
 	scheduleTable := `
 	CREATE TABLE IF NOT EXISTS schedule (
 		statcrew_id TEXT PRIMARY KEY,
@@ -172,7 +347,8 @@ func createTables() {
 		away_score INTEGER,
 		slug TEXT,
 		game_date TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);`
 
 	scoreboardTable := `
@@ -185,215 +361,820 @@ func createTables() {
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);`
 
-	_, err := db.Exec(scheduleTable)
-	if err != nil {
-		log.Fatal(err)
+	boxscoreTable := `
+	CREATE TABLE IF NOT EXISTS boxscore (
+		statcrew_id TEXT PRIMARY KEY,
+		data TEXT,
+		fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	teamsTable := `
+	CREATE TABLE IF NOT EXISTS teams (
+		name TEXT PRIMARY KEY,
+		division TEXT NOT NULL DEFAULT '',
+		logo TEXT NOT NULL DEFAULT '',
+		color TEXT NOT NULL DEFAULT ''
+	);`
+
+	scoreHistoryTable := `
+	CREATE TABLE IF NOT EXISTS score_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		statcrew_id TEXT NOT NULL,
+		old_home_score INTEGER,
+		old_away_score INTEGER,
+		new_home_score INTEGER,
+		new_away_score INTEGER,
+		changed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_score_history_game ON score_history (statcrew_id);`
+
+	metadataTable := `
+	CREATE TABLE IF NOT EXISTS metadata (
+		key TEXT PRIMARY KEY,
+		value TEXT,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	for _, table := range []string{scheduleTable, scoreboardTable, boxscoreTable, scoreHistoryTable, teamsTable, metadataTable} {
+		if _, err := db.Exec(table); err != nil {
+			return err
+		}
 	}
 
-	_, err = db.Exec(scoreboardTable)
+	// Databases created before updated_at existed start with the creation
+	// time. Without the backfill the column is just empty, so that's no
+	// reason to stop.
+	if err := addColumn("schedule", "updated_at", "DATETIME"); err != nil {
+		return err
+	}
+	if _, err := db.Exec("UPDATE schedule SET updated_at = created_at WHERE updated_at IS NULL"); err != nil {
+		logErrorf("Error backfilling schedule.updated_at: %v", err)
+	}
+
+	// Games stored before leagues existed belong to the primary league
+	if err := addColumn("schedule", "league", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if _, err := db.Exec("UPDATE schedule SET league = ? WHERE league = ''", primaryLeague()); err != nil {
+		logErrorf("Error backfilling schedule.league: %v", err)
+	}
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_schedule_league ON schedule (league)"); err != nil {
+		return err
+	}
+
+	logInfof("Database tables created successfully")
+	return nil
+}
+
+// addColumn adds a column to an existing table unless it is already there
+func addColumn(table, column, definition string) error {
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?)", table)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	log.Println("Database tables created successfully")
+	logInfof("Adding column %s.%s", table, column)
+	_, err = db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	return err
 }
 
 func startDataFetcher() {
 	c := cron.New()
 
-	// Fetch data every 5 minutes
-	c.AddFunc("*/5 * * * *", func() {
-		log.Println("Fetching new data...")
+	// Fetch data on the active season schedule, every 5 minutes by default,
+	// and switch to the offseason schedule when no games are coming up
+	var scheduler *fetchScheduler
+	fetchData := func() {
+		logInfof("Fetching new data...")
 		fetchSchedule()
-		// No longer need to fetch scoreboard since we calculate it from schedule
-	})
+		// Standings are calculated from the schedule, the scoreboard only
+		// fills in scores the schedule doesn't have yet
+		if config.FetchScoreboard {
+			fetchScoreboard()
+		}
+		if config.FetchBoxScores {
+			fetchBoxScores()
+		}
+		scheduler.update()
+	}
+	scheduler = &fetchScheduler{cron: c, job: fetchData}
+	if err := scheduler.start(); err != nil {
+		log.Fatalf("Failed to schedule the data fetcher: %v", err)
+	}
+
+	// Optionally remove old seasons
+	if config.RetentionSeasons > 0 {
+		if _, err := c.AddFunc(config.RetentionCron, cleanupOldSeasons); err != nil {
+			logWarnf("Invalid GOELF_RETENTION_CRON %q, retention cleanup disabled: %v", config.RetentionCron, err)
+		} else {
+			logInfof("Retention cleanup enabled, keeping %d seasons (%s)", config.RetentionSeasons, config.RetentionCron)
+		}
+	}
+
+	// Optionally re-download and compare the whole schedule every night
+	if config.ResyncCron != "" {
+		if _, err := c.AddFunc(config.ResyncCron, resyncSchedule); err != nil {
+			logWarnf("Invalid GOELF_RESYNC_CRON %q, full re-sync disabled: %v", config.ResyncCron, err)
+		} else {
+			logInfof("Full re-sync enabled (%s)", config.ResyncCron)
+		}
+	}
 
 	c.Start()
 
-	// Initial fetch with fallback to mock data
+	if !config.FetchOnStart {
+		logInfof("Fetch on start disabled, waiting for the first scheduled fetch or a manual refresh")
+		return
+	}
+
+	// Initial fetch with optional fallback to mock data
 	go func() {
-		time.Sleep(2 * time.Second)
+		time.Sleep(config.StartupDelay)
 		fetchSchedule()
+		if config.FetchScoreboard {
+			fetchScoreboard()
+		}
+		if config.FetchBoxScores {
+			fetchBoxScores()
+		}
+		defer scheduler.update()
+
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
 
-		// If no schedule data was fetched, insert mock data
 		var scheduleCount int
-		err := db.QueryRow("SELECT COUNT(*) FROM schedule").Scan(&scheduleCount)
+		err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schedule").Scan(&scheduleCount)
+		if err != nil || scheduleCount > 0 {
+			return
+		}
 
-		if err == nil && scheduleCount == 0 {
-			log.Println("No data fetched from APIs, inserting mock data...")
+		// If no schedule data was fetched, only insert mock data when enabled
+		if config.EnableMock {
+			logInfof("No data fetched from APIs, inserting mock data (GOELF_ENABLE_MOCK=true)...")
 			insertMockData()
+		} else {
+			logInfof("No data fetched from APIs, leaving schedule empty (set GOELF_ENABLE_MOCK=true to use mock data)")
 		}
 	}()
 }
 
-func fetchSchedule() {
+// scheduleFetchMu and scoreboardFetchMu make sure only one fetch of each
+// runs at a time, e.g. when the upstream is slower than the cron interval
+var (
+	scheduleFetchMu   sync.Mutex
+	scoreboardFetchMu sync.Mutex
+)
+
+// errFetchRunning is returned by fetchSchedule and fetchScoreboard when the
+// previous fetch hasn't finished yet
+var errFetchRunning = errors.New("fetch still running")
+
+// fetchSchedule downloads the schedule of every league from the upstream,
+// stores it and records the outcome for the health check, returning the
+// changes per league. It is skipped if another schedule fetch is still
+// running.
+func fetchSchedule() (map[string]storeCounts, error) {
+	if !scheduleFetchMu.TryLock() {
+		logWarnf("Schedule fetch still running, skipping")
+		return nil, errFetchRunning
+	}
+	defer scheduleFetchMu.Unlock()
+
+	counts := make(map[string]storeCounts, len(config.Leagues))
+	var errs []error
+	for _, league := range config.Leagues {
+		leagueCounts, err := updateSchedule(league, false)
+		if err != nil {
+			logErrorf("Error fetching schedule of league %s: %v", league.ID, err)
+			errs = append(errs, fmt.Errorf("%s: %w", league.ID, err))
+			continue
+		}
+		counts[league.ID] = leagueCounts
+	}
+	err := errors.Join(errs...)
+	recordFetchResult(err)
+	return counts, err
+}
+
+// errResponseTooLarge is returned by readResponseBody for oversized bodies
+var errResponseTooLarge = errors.New("response exceeds the limit")
+
+// readResponseBody reads an upstream response body, failing instead of
+// buffering it if it is larger than GOELF_MAX_RESPONSE_BYTES
+func readResponseBody(body io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, config.MaxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > config.MaxResponseBytes {
+		return nil, fmt.Errorf("%w of %d bytes", errResponseTooLarge, config.MaxResponseBytes)
+	}
+	return data, nil
+}
+
+// Schedule source retries before falling back to the next source
+const scheduleSourceAttempts = 2
+
+// scheduleRetryDelay is the pause before retrying an unavailable source,
+// shortened by the tests
+var scheduleRetryDelay = 2 * time.Second
+
+// scheduleResponse is a successful download of the schedule
+type scheduleResponse struct {
+	source      string
+	body        []byte // nil if the schedule was not modified
+	header      http.Header
+	notModified bool
+}
+
+// errSourceUnavailable marks failures worth retrying: network errors and
+// 5xx responses
+var errSourceUnavailable = errors.New("schedule source unavailable")
+
+// httpStatusError is an unexpected HTTP status of the upstream
+type httpStatusError struct {
+	code int
+}
+
+func (e httpStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.code)
+}
+
+// requestSchedule tries the schedule sources of GOELF_SCHEDULE_URLS in
+// order. A source that is unavailable is retried before falling back to the
+// next one. With force the schedule is downloaded even if it didn't change.
+func requestSchedule(league League, force bool) (scheduleResponse, error) {
+	var errs []error
+	for _, source := range league.URLs {
+		for attempt := 1; attempt <= scheduleSourceAttempts; attempt++ {
+			resp, err := requestScheduleSource(source, league.ID, force)
+			if err == nil {
+				return resp, nil
+			}
+			logWarnf("Schedule fetch from %s failed (attempt %d/%d): %v", source, attempt, scheduleSourceAttempts, err)
+			if !errors.Is(err, errSourceUnavailable) || attempt == scheduleSourceAttempts {
+				errs = append(errs, fmt.Errorf("%s: %w", source, err))
+				break
+			}
+			time.Sleep(scheduleRetryDelay)
+		}
+	}
+	return scheduleResponse{}, errors.Join(errs...)
+}
+
+// requestScheduleSource downloads the schedule from a single source. The
+// stored ETag and Last-Modified are only sent to the source they came from,
+// and not at all with force.
+func requestScheduleSource(source, league string, force bool) (scheduleResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
 	// Create a new request with the required Referer header
-	req, err := http.NewRequest("GET", "https://europeanleague.football/api/schedule", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", source, nil)
 	if err != nil {
-		log.Printf("Error creating schedule request: %v", err)
-		return
+		return scheduleResponse{}, fmt.Errorf("creating request: %w", err)
 	}
 
 	// Add the required Referer header
 	req.Header.Set("Referer", "https://europeanleague.football/games/schedule")
 
+	// Only download the schedule again if it changed since the last fetch,
+	// unless forced
+	if lastSource, err := getMetadata(ctx, leagueMetaKey(metaScheduleSource, league)); err != nil {
+		logErrorf("Error reading schedule source: %v", err)
+	} else if lastSource == source && !force {
+		if etag, err := getMetadata(ctx, leagueMetaKey(metaScheduleETag, league)); err != nil {
+			logErrorf("Error reading schedule ETag: %v", err)
+		} else if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified, err := getMetadata(ctx, leagueMetaKey(metaScheduleLastModified, league)); err != nil {
+			logErrorf("Error reading schedule Last-Modified: %v", err)
+		} else if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
 	// Make the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient().Do(req)
 	if err != nil {
-		log.Printf("Error fetching schedule: %v", err)
-		return
+		return scheduleResponse{}, fmt.Errorf("%w: %w", errSourceUnavailable, err)
 	}
 	defer resp.Body.Close()
 
-	log.Printf("Schedule API HTTP status: %d", resp.StatusCode)
+	logDebugf("Schedule API HTTP status: %d", resp.StatusCode)
+
+	if resp.StatusCode == http.StatusNotModified {
+		return scheduleResponse{source: source, header: resp.Header, notModified: true}, nil
+	}
 
 	// Check for HTTP errors
+	if resp.StatusCode >= 500 {
+		return scheduleResponse{}, fmt.Errorf("%w: %w", errSourceUnavailable, httpStatusError{resp.StatusCode})
+	}
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("Schedule API returned HTTP %d - API may be temporarily unavailable", resp.StatusCode)
-		return
+		return scheduleResponse{}, fmt.Errorf("schedule API returned %w", httpStatusError{resp.StatusCode})
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponseBody(resp.Body)
 	if err != nil {
-		log.Printf("Error reading schedule response: %v", err)
-		return
+		return scheduleResponse{}, fmt.Errorf("reading response: %w", err)
 	}
+	dumpResponse("schedule", body)
 
 	// Check if response is empty or invalid
 	if len(body) == 0 {
-		log.Printf("Schedule API returned empty response")
-		return
+		return scheduleResponse{}, fmt.Errorf("schedule API returned empty response")
+	}
+	return scheduleResponse{source: source, body: body, header: resp.Header}, nil
+}
+
+// updateSchedule downloads the schedule of a league and stores it, returning
+// how the stored games changed. With force the upstream's validators are
+// ignored, so the whole schedule is downloaded and compared even if it is
+// unchanged.
+func updateSchedule(league League, force bool) (storeCounts, error) {
+	resp, err := requestSchedule(league, force)
+	if err != nil {
+		return storeCounts{}, err
+	}
+	if league.ID == primaryLeague() {
+		recordFetchSource(resp.source)
 	}
 
+	if resp.notModified {
+		logInfof("Schedule not modified since last fetch from %s, skipping update", resp.source)
+		return storeCounts{}, nil
+	}
+	body := resp.body
+
+	ctx, cancel := context.WithTimeout(withLeague(context.Background(), league.ID), fetchTimeout)
+	defer cancel()
+
 	// Log the first 500 characters of the response for debugging
 	if len(body) > 500 {
-		log.Printf("Schedule API response (first 500 chars): %s", string(body[:500]))
+		logDebugf("Schedule API response (first 500 chars): %s", string(body[:500]))
 	} else {
-		log.Printf("Schedule API response: %s", string(body))
+		logDebugf("Schedule API response: %s", string(body))
 	}
 
 	var schedules []Schedule
-	if err := json.Unmarshal(body, &schedules); err != nil {
-		log.Printf("Error parsing schedule JSON: %v", err)
-		log.Printf("Response body: %s", string(body))
-		return
+	if err := decodeTolerant(body, &schedules); err != nil {
+		logDebugf("Response body: %s", string(body))
+		return storeCounts{}, fmt.Errorf("parsing JSON: %w", err)
 	}
 
-	// Clear existing data and insert new
-	_, err = db.Exec("DELETE FROM schedule")
+	// Clean up whitespace and casing, then skip malformed entries before
+	// touching the stored data
+	normalizeSchedules(schedules, league.ID)
+	schedules, skipped := filterValidSchedules(schedules)
+	if skipped > 0 {
+		logWarnf("Skipped %d invalid schedule entries", skipped)
+	}
+	warnMissingLocations(schedules)
+	warnUndatedGames(schedules)
+
+	counts, err := storeSchedules(ctx, schedules, true)
 	if err != nil {
-		log.Printf("Error clearing schedule: %v", err)
-		return
+		return counts, err
+	}
+	warnUnmappedTeams(schedules, league.ID)
+
+	// Remember the validators for the next conditional request
+	if err := setMetadata(ctx, leagueMetaKey(metaScheduleSource, league.ID), resp.source); err != nil {
+		logErrorf("Error storing schedule source: %v", err)
+	}
+	if err := setMetadata(ctx, leagueMetaKey(metaScheduleETag, league.ID), resp.header.Get("ETag")); err != nil {
+		logErrorf("Error storing schedule ETag: %v", err)
+	}
+	if err := setMetadata(ctx, leagueMetaKey(metaScheduleLastModified, league.ID), resp.header.Get("Last-Modified")); err != nil {
+		logErrorf("Error storing schedule Last-Modified: %v", err)
+	}
+
+	logInfof("Fetched %d schedule entries of league %s from %s", len(schedules), league.ID, resp.source)
+	return counts, nil
+}
+
+// upsertScheduleQuery inserts a game or updates it if any stored value
+// differs. Unchanged rows are left alone, so their created_at and updated_at
+// are kept.
+const upsertScheduleQuery = `
+	INSERT INTO schedule (statcrew_id, home_team, away_team, date, time, game_week, location, home_score, away_score, slug, game_date, league, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	ON CONFLICT(statcrew_id) DO UPDATE SET
+		home_team = excluded.home_team,
+		away_team = excluded.away_team,
+		date = excluded.date,
+		time = excluded.time,
+		game_week = excluded.game_week,
+		location = excluded.location,
+		home_score = excluded.home_score,
+		away_score = excluded.away_score,
+		slug = excluded.slug,
+		game_date = excluded.game_date,
+		league = excluded.league,
+		updated_at = excluded.updated_at
+	WHERE home_team IS NOT excluded.home_team
+		OR away_team IS NOT excluded.away_team
+		OR date IS NOT excluded.date
+		OR time IS NOT excluded.time
+		OR game_week IS NOT excluded.game_week
+		OR location IS NOT excluded.location
+		OR home_score IS NOT excluded.home_score
+		OR away_score IS NOT excluded.away_score
+		OR slug IS NOT excluded.slug
+		OR game_date IS NOT excluded.game_date
+		OR league IS NOT excluded.league`
+
+// storeCounts tells how storeSchedules changed the stored games
+type storeCounts struct {
+	Inserted  int `json:"inserted"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Removed   int `json:"removed"`
+}
+
+// storeSchedules upserts the games in one transaction: new games are inserted
+// and changed games updated. With sync the games are the upstream's complete
+// schedule, so stored games missing from it are removed and new results are
+// announced through the webhook. The games belong to the league of the
+// context and only that league's games are removed.
+func storeSchedules(ctx context.Context, schedules []Schedule, sync bool) (storeCounts, error) {
+	league := leagueFromContext(ctx)
+
+	// Store every team under its canonical name, whatever the source calls it
+	applyTeamAliases(schedules)
+
+	var counts storeCounts
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return counts, fmt.Errorf("starting transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if len(schedules) > 0 {
-		stmt, err := db.Prepare("REPLACE INTO schedule (statcrew_id, home_team, away_team, date, time, game_week, location, home_score, away_score, slug, game_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	// Remember which games exist and their scores to tell inserts from
+	// updates and to notice new results
+	existing := make(map[string][2]int)
+	rows, err := tx.QueryContext(ctx, "SELECT statcrew_id, home_score, away_score FROM schedule WHERE league = ?", league)
+	if err != nil {
+		return counts, fmt.Errorf("reading schedule: %w", err)
+	}
+	for rows.Next() {
+		var id string
+		var score [2]int
+		if err := rows.Scan(&id, &score[0], &score[1]); err != nil {
+			rows.Close()
+			return counts, fmt.Errorf("reading schedule: %w", err)
+		}
+		existing[id] = score
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return counts, fmt.Errorf("reading schedule: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, upsertScheduleQuery)
+	if err != nil {
+		return counts, fmt.Errorf("preparing schedule statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var results []Schedule
+	fetched := make(map[string]bool, len(schedules))
+	for _, schedule := range schedules {
+		fetched[schedule.StatcrewID] = true
+		result, err := stmt.ExecContext(ctx, schedule.StatcrewID, schedule.HomeTeam, schedule.AwayTeam, schedule.Date, schedule.Time, schedule.GameWeek, schedule.Location, schedule.HomeScore, schedule.AwayScore, schedule.Slug, schedule.GameDate, league)
 		if err != nil {
-			log.Printf("Error preparing schedule statement: %v", err)
-			return
+			logErrorf("Error storing schedule %s: %v", schedule.StatcrewID, err)
+			continue
+		}
+		affected, err := result.RowsAffected()
+		previous, existed := existing[schedule.StatcrewID]
+		switch {
+		case err != nil:
+			logErrorf("Error storing schedule %s: %v", schedule.StatcrewID, err)
+			continue
+		case affected == 0:
+			counts.Unchanged++
+			continue
+		case existed:
+			counts.Updated++
+		default:
+			counts.Inserted++
 		}
-		defer stmt.Close()
 
-		for _, schedule := range schedules {
-			_, err = stmt.Exec(schedule.StatcrewID, schedule.HomeTeam, schedule.AwayTeam, schedule.Date, schedule.Time, schedule.GameWeek, schedule.Location, schedule.HomeScore, schedule.AwayScore, schedule.Slug, schedule.GameDate)
-			if err != nil {
-				log.Printf("Error inserting schedule: %v", err)
+		score := [2]int{schedule.HomeScore, schedule.AwayScore}
+		if isPlayed(schedule.HomeScore, schedule.AwayScore) && previous != score {
+			results = append(results, schedule)
+		}
+		if config.ScoreHistory && existed && previous != score {
+			if err := recordScoreChange(ctx, tx, schedule.StatcrewID, previous, score); err != nil {
+				return counts, err
 			}
 		}
 	}
 
-	log.Printf("Fetched %d schedule entries", len(schedules))
+	// Remove games the upstream no longer lists
+	for id := range existing {
+		if !sync || fetched[id] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM schedule WHERE statcrew_id = ?", id); err != nil {
+			return counts, fmt.Errorf("removing schedule %s: %w", id, err)
+		}
+		counts.Removed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return counts, fmt.Errorf("committing schedule: %w", err)
+	}
+
+	logInfof("Schedule stored: %d inserted, %d updated, %d unchanged, %d removed", counts.Inserted, counts.Updated, counts.Unchanged, counts.Removed)
+	if counts.Inserted+counts.Updated+counts.Removed > 0 {
+		invalidateStandings()
+	}
+
+	// Announce new and corrected results, except when loading into an empty
+	// database where every played game would be "new"
+	if sync && len(existing) > 0 {
+		notifyResults(results)
+	}
+	return counts, nil
+}
+
+// missingLocationRatio is the share of games without a venue above which a
+// fetch logs a warning, as it likely means the upstream renamed the field
+const missingLocationRatio = 0.5
+
+// warnMissingLocations logs a warning if most games have no location
+func warnMissingLocations(schedules []Schedule) {
+	missing := 0
+	for _, s := range schedules {
+		if strings.TrimSpace(s.Location) == "" {
+			missing++
+		}
+	}
+	if len(schedules) > 0 && float64(missing)/float64(len(schedules)) > missingLocationRatio {
+		logWarnf("%d of %d games have no location, the upstream may have changed the field", missing, len(schedules))
+	}
+}
+
+// validateSchedule checks that a game can be stored: it needs an ID (the
+// primary key) and both team names
+func validateSchedule(s Schedule) error {
+	if strings.TrimSpace(s.StatcrewID) == "" {
+		return fmt.Errorf("missing statcrewID")
+	}
+	if strings.TrimSpace(s.HomeTeam) == "" {
+		return fmt.Errorf("missing home team")
+	}
+	if strings.TrimSpace(s.AwayTeam) == "" {
+		return fmt.Errorf("missing away team")
+	}
+	return nil
+}
+
+// filterValidSchedules drops and logs all games that fail validation and
+// returns the valid ones with the number of skipped entries
+func filterValidSchedules(schedules []Schedule) ([]Schedule, int) {
+	valid := make([]Schedule, 0, len(schedules))
+	for i, schedule := range schedules {
+		if err := validateSchedule(schedule); err != nil {
+			logWarnf("Skipping schedule entry %d (statcrewID %q): %v", i, schedule.StatcrewID, err)
+			continue
+		}
+		valid = append(valid, schedule)
+	}
+	return valid, len(schedules) - len(valid)
+}
+
+// fetchScoreboard downloads the scoreboard and replaces the stored one,
+// returning the number of entries. It is skipped if another scoreboard fetch
+// is still running.
+func fetchScoreboard() (int, error) {
+	if !scoreboardFetchMu.TryLock() {
+		logWarnf("Scoreboard fetch still running, skipping")
+		return 0, errFetchRunning
+	}
+	defer scoreboardFetchMu.Unlock()
+
+	count, err := updateScoreboard()
+	if err != nil {
+		logErrorf("Error fetching scoreboard: %v", err)
+	}
+	return count, err
 }
 
-func fetchScoreboard() {
-	resp, err := http.Get("https://europeanleague.football/api/scoreboard")
+// updateScoreboard does the work of fetchScoreboard
+func updateScoreboard() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://europeanleague.football/api/scoreboard", nil)
 	if err != nil {
-		log.Printf("Error fetching scoreboard: %v", err)
-		return
+		return 0, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return 0, err
 	}
 	defer resp.Body.Close()
 
-	log.Printf("Scoreboard API HTTP status: %d", resp.StatusCode)
+	logDebugf("Scoreboard API HTTP status: %d", resp.StatusCode)
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("Scoreboard API returned HTTP %d - API may be temporarily unavailable", resp.StatusCode)
-		return
+		return 0, fmt.Errorf("scoreboard API returned HTTP %d - API may be temporarily unavailable", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponseBody(resp.Body)
 	if err != nil {
-		log.Printf("Error reading scoreboard response: %v", err)
-		return
+		return 0, fmt.Errorf("reading response: %w", err)
 	}
+	dumpResponse("scoreboard", body)
 
 	// Check if response is empty or invalid
 	if len(body) == 0 {
-		log.Printf("Scoreboard API returned empty response")
-		return
+		return 0, fmt.Errorf("scoreboard API returned empty response")
 	}
 
 	// Log the first 200 characters of the response for debugging
 	if len(body) > 200 {
-		log.Printf("Scoreboard API response (first 200 chars): %s", string(body[:200]))
+		logDebugf("Scoreboard API response (first 200 chars): %s", string(body[:200]))
 	} else {
-		log.Printf("Scoreboard API response: %s", string(body))
+		logDebugf("Scoreboard API response: %s", string(body))
 	}
 
 	var scoreboards []Scoreboard
-	if err := json.Unmarshal(body, &scoreboards); err != nil {
-		log.Printf("Error parsing scoreboard JSON: %v", err)
-		log.Printf("Response body: %s", string(body))
-		return
+	if err := decodeTolerant(body, &scoreboards); err != nil {
+		logDebugf("Response body: %s", string(body))
+		return 0, fmt.Errorf("parsing JSON: %w", err)
 	}
 
 	// Clear existing data and insert new
-	_, err = db.Exec("DELETE FROM scoreboard")
+	_, err = db.ExecContext(ctx, "DELETE FROM scoreboard")
 	if err != nil {
-		log.Printf("Error clearing scoreboard: %v", err)
-		return
+		return 0, fmt.Errorf("clearing scoreboard: %w", err)
 	}
+	defer invalidateStandings()
 
 	if len(scoreboards) > 0 {
-		stmt, err := db.Prepare("REPLACE INTO scoreboard (statcrew_id, home_score, away_score, home_record, away_record) VALUES (?, ?, ?, ?, ?)")
+		stmt, err := db.PrepareContext(ctx, "REPLACE INTO scoreboard (statcrew_id, home_score, away_score, home_record, away_record) VALUES (?, ?, ?, ?, ?)")
 		if err != nil {
-			log.Printf("Error preparing scoreboard statement: %v", err)
-			return
+			return 0, fmt.Errorf("preparing scoreboard statement: %w", err)
 		}
 		defer stmt.Close()
 
 		for _, scoreboard := range scoreboards {
-			_, err = stmt.Exec(scoreboard.StatcrewID, scoreboard.HomeScore, scoreboard.AwayScore, scoreboard.HomeRecord, scoreboard.AwayRecord)
+			_, err = stmt.ExecContext(ctx, scoreboard.StatcrewID, scoreboard.HomeScore, scoreboard.AwayScore, scoreboard.HomeRecord, scoreboard.AwayRecord)
 			if err != nil {
-				log.Printf("Error inserting scoreboard: %v", err)
+				logErrorf("Error inserting scoreboard: %v", err)
 			}
 		}
 	}
 
-	log.Printf("Fetched %d scoreboard entries", len(scoreboards))
+	logInfof("Fetched %d scoreboard entries", len(scoreboards))
+	return len(scoreboards), nil
 }
 
-func getSchedule(c *gin.Context) {
-	rows, err := db.Query("SELECT statcrew_id, home_team, away_team, date, time, game_week, location, home_score, away_score, slug, game_date FROM schedule ORDER BY date, time")
+// getScheduleWeek returns the games of a single game week
+func getScheduleWeek(c *gin.Context) {
+	display, err := wantsDisplayDates(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	week, ok, err := resolveGameWeek(c.Request.Context(), c.Param("n"))
+	if errors.Is(err, errInvalidGameWeek) {
+		respondError(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+	if !ok {
+		respondError(c, http.StatusNotFound, codeNotFound, "no scheduled games")
+		return
+	}
+
+	schedules, err := loadDisplaySchedule(c.Request.Context(), scheduleSortOrders["date"])
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+	stripDisplayDates(schedules, display)
+	markFavoriteGames(schedules, requestFavorites(c))
+
+	gameWeek := GameWeek{Week: week}
+	for _, match := range schedules {
+		if match.GameWeek == week {
+			gameWeek.Matches = append(gameWeek.Matches, match)
+		}
+	}
+
+	if len(gameWeek.Matches) == 0 {
+		respondError(c, http.StatusNotFound, codeNotFound, fmt.Sprintf("no games in week %d", week))
+		return
+	}
+
+	// Check if request is from HTMX (has HX-Request header)
+	if wantsHTML(c) {
+		c.HTML(http.StatusOK, "week.html", gameWeek)
+	} else {
+		c.JSON(http.StatusOK, gameWeek)
+	}
+}
+
+// getScheduleRange returns the games between ?from= and ?to= (YYYY-MM-DD,
+// both inclusive)
+func getScheduleRange(c *gin.Context) {
+	display, err := wantsDisplayDates(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeBadRequest, "from must be a date like 2025-05-17")
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeBadRequest, "to must be a date like 2025-05-17")
+		return
+	}
+	if from.After(to) {
+		respondError(c, http.StatusBadRequest, codeBadRequest, "from must not be after to")
+		return
+	}
+
+	schedules, err := queryDisplaySchedule(c.Request.Context(), scheduleSortOrders["date"],
+		"substr(date, 1, 10) BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDBError(c, err)
 		return
 	}
+	stripDisplayDates(schedules, display)
+	markFavoriteGames(schedules, requestFavorites(c))
+
+	if schedules == nil {
+		schedules = []Schedule{}
+	}
+	respondJSON(c, http.StatusOK, schedules)
+}
+
+// loadDisplaySchedule reads all games in the given order, adds the team logos
+// and game status and formats date and time for display
+func loadDisplaySchedule(ctx context.Context, orderBy string) ([]Schedule, error) {
+	return queryDisplaySchedule(ctx, orderBy, "1 = 1")
+}
+
+// queryDisplaySchedule is loadDisplaySchedule for the games matching the
+// WHERE condition
+func queryDisplaySchedule(ctx context.Context, orderBy, where string, args ...interface{}) ([]Schedule, error) {
+	args = append([]interface{}{leagueFromContext(ctx)}, args...)
+	rows, err := db.QueryContext(ctx, "SELECT "+scheduleColumns+" FROM schedule WHERE league = ? AND ("+where+") ORDER BY "+orderBy, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	var schedules []Schedule
+	now := time.Now()
+	var schedules, undated []Schedule
 	for rows.Next() {
-		var s Schedule
-		err := rows.Scan(&s.StatcrewID, &s.HomeTeam, &s.AwayTeam, &s.Date, &s.Time, &s.GameWeek, &s.Location, &s.HomeScore, &s.AwayScore, &s.Slug, &s.GameDate)
+		s, err := scanSchedule(rows)
 		if err != nil {
-			log.Printf("Error scanning schedule: %v", err)
+			logErrorf("Error scanning schedule: %v", err)
 			continue
 		}
+		_, dated := parseGameTime(s)
 		// Add team logos
 		s.HomeLogo = teamLogos[s.HomeTeam]
 		s.AwayLogo = teamLogos[s.AwayTeam]
 
+		// Derive the game state before the date is reformatted
+		s.Status = gameStatus(s, now)
+		s.played = gamePlayed(s, now)
+		s.DisplayDate = formatDisplayDate(s)
+
 		// Format date to DD.MM
 		if len(s.Date) >= 10 {
 			// Parse the date (format: "2025-05-17T19:00:00.000Z")
@@ -405,27 +1186,146 @@ func getSchedule(c *gin.Context) {
 			}
 		}
 
-		// Format time to hh:mm
-		if len(s.Time) >= 5 {
-			// Check if time is in format like "19:00:00" or "19:00"
-			if (len(s.Time) >= 8 && s.Time[2] == ':' && s.Time[5] == ':') ||
-				(len(s.Time) >= 5 && s.Time[2] == ':') {
-				// Extract hours and minutes
-				hours := s.Time[:2]
-				minutes := s.Time[3:5]
-				s.Time = hours + ":" + minutes
+		// Format time to hh:mm
+		if len(s.Time) >= 5 {
+			// Check if time is in format like "19:00:00" or "19:00"
+			if (len(s.Time) >= 8 && s.Time[2] == ':' && s.Time[5] == ':') ||
+				(len(s.Time) >= 5 && s.Time[2] == ':') {
+				// Extract hours and minutes
+				hours := s.Time[:2]
+				minutes := s.Time[3:5]
+				s.Time = hours + ":" + minutes
+			}
+		}
+
+		if dated {
+			schedules = append(schedules, s)
+		} else {
+			undated = append(undated, s)
+		}
+	}
+
+	// The dates are reformatted by now, so apply GOELF_UNDATED_GAMES with
+	// what was parsed above instead of applyUndatedPolicy
+	if config.UndatedGames != undatedExclude {
+		schedules = append(schedules, undated...)
+	}
+	return schedules, rows.Err()
+}
+
+// scheduleSortOrders maps the allowed ?sort= values to ORDER BY clauses
+var scheduleSortOrders = map[string]string{
+	"date":      "date, time",
+	"-date":     "date DESC, time DESC",
+	"gameweek":  "game_week, date, time",
+	"-gameweek": "game_week DESC, date, time",
+}
+
+// filterLocation keeps the games whose location contains the query, ignoring
+// case and accents. An empty query keeps all games.
+func filterLocation(games []Schedule, query string) []Schedule {
+	query = normalizeTeamName(query)
+	if query == "" {
+		return games
+	}
+
+	var filtered []Schedule
+	for _, game := range games {
+		if strings.Contains(normalizeTeamName(game.Location), query) {
+			filtered = append(filtered, game)
+		}
+	}
+	return filtered
+}
+
+// getSchedule returns the schedule as JSON (or HTML for HTMX requests), or as
+// CSV or iCalendar depending on the Accept header
+func getSchedule(c *gin.Context) {
+	if wantsHTML(c) {
+		getScheduleData(c)
+		return
+	}
+
+	switch negotiateFormat(c, mimeJSON, mimeCSV, mimeCalendar) {
+	case mimeCSV:
+		getScheduleCSV(c)
+	case mimeCalendar:
+		getScheduleCalendar(c)
+	default:
+		getScheduleData(c)
+	}
+}
+
+// getScheduleData returns the finished and upcoming games grouped by week
+func getScheduleData(c *gin.Context) {
+	// Optional sparse fieldset, e.g. ?fields=homename,awayname,date
+	fields, err := parseFields(c.Query("fields"), reflect.TypeOf(Schedule{}))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	// Sorting only uses allowlisted ORDER BY clauses, never user input
+	// GOELF_DEFAULT_SORT stands in for a missing ?sort=
+	sortKey, sortGiven := c.GetQuery("sort")
+	if !sortGiven && config.DefaultSort != "" {
+		sortKey, sortGiven = config.DefaultSort, true
+	}
+	if !sortGiven {
+		sortKey = "date"
+	}
+	orderBy, ok := scheduleSortOrders[sortKey]
+	if !ok {
+		respondError(c, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("unknown sort %q", sortKey))
+		return
+	}
+
+	// Optionally add the formatted kickoff, ?format=display
+	display, err := wantsDisplayDates(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	schedules, err := loadDisplaySchedule(c.Request.Context(), orderBy)
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+	stripDisplayDates(schedules, display)
+	markFavoriteGames(schedules, requestFavorites(c))
+
+	// Optionally only return a single week, ?gameweek=current for the
+	// current one
+	if value := c.Query("gameweek"); value != "" {
+		week, ok, err := resolveGameWeek(c.Request.Context(), value)
+		if errors.Is(err, errInvalidGameWeek) {
+			respondError(c, http.StatusBadRequest, codeBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			respondDBError(c, err)
+			return
+		}
+
+		var filtered []Schedule
+		for _, match := range schedules {
+			if ok && match.GameWeek == week {
+				filtered = append(filtered, match)
 			}
 		}
-
-		schedules = append(schedules, s)
+		schedules = filtered
 	}
 
+	// Optionally only return the games at a venue, e.g. ?location=frankfurt
+	schedules = filterLocation(schedules, c.Query("location"))
+
 	// Separate finished and upcoming matches
 	var finishedMatches []Schedule
 	var upcomingMatches []Schedule
 
 	for _, match := range schedules {
-		if match.HomeScore > 0 || match.AwayScore > 0 {
+		if match.played {
 			finishedMatches = append(finishedMatches, match)
 		} else {
 			upcomingMatches = append(upcomingMatches, match)
@@ -445,33 +1345,25 @@ func getSchedule(c *gin.Context) {
 	}
 
 	// Convert to sorted slices
-	var sortedFinishedWeeks []GameWeek
+	sortedFinishedWeeks := []GameWeek{}
 	for week, matches := range finishedGameWeeks {
 		sortedFinishedWeeks = append(sortedFinishedWeeks, GameWeek{Week: week, Matches: matches})
 	}
 
-	var sortedUpcomingWeeks []GameWeek
+	sortedUpcomingWeeks := []GameWeek{}
 	for week, matches := range upcomingGameWeeks {
 		sortedUpcomingWeeks = append(sortedUpcomingWeeks, GameWeek{Week: week, Matches: matches})
 	}
 
-	// Sort finished matches by week (latest to oldest)
-	for i := 0; i < len(sortedFinishedWeeks)-1; i++ {
-		for j := i + 1; j < len(sortedFinishedWeeks); j++ {
-			if sortedFinishedWeeks[i].Week < sortedFinishedWeeks[j].Week {
-				sortedFinishedWeeks[i], sortedFinishedWeeks[j] = sortedFinishedWeeks[j], sortedFinishedWeeks[i]
-			}
-		}
-	}
-
-	// Sort upcoming matches by week (oldest to newest)
-	for i := 0; i < len(sortedUpcomingWeeks)-1; i++ {
-		for j := i + 1; j < len(sortedUpcomingWeeks); j++ {
-			if sortedUpcomingWeeks[i].Week > sortedUpcomingWeeks[j].Week {
-				sortedUpcomingWeeks[i], sortedUpcomingWeeks[j] = sortedUpcomingWeeks[j], sortedUpcomingWeeks[i]
-			}
-		}
+	// Weeks follow the direction of ?sort= or GOELF_DEFAULT_SORT. Without
+	// either the latest results and the next games come first.
+	finishedDescending, upcomingDescending := true, false
+	if sortGiven {
+		finishedDescending = strings.HasPrefix(sortKey, "-")
+		upcomingDescending = finishedDescending
 	}
+	sortGameWeeks(sortedFinishedWeeks, finishedDescending)
+	sortGameWeeks(sortedUpcomingWeeks, upcomingDescending)
 
 	// Create schedule data structure
 	scheduleData := ScheduleData{
@@ -480,29 +1372,143 @@ func getSchedule(c *gin.Context) {
 	}
 
 	// Check if request is from HTMX (has HX-Request header)
-	if c.GetHeader("HX-Request") == "true" {
+	if wantsHTML(c) {
 		c.HTML(http.StatusOK, "schedule.html", scheduleData)
+		return
+	}
+
+	var data interface{} = scheduleData
+	if fields != nil {
+		data = gin.H{
+			"FinishedMatches": sparseGameWeeks(sortedFinishedWeeks, fields),
+			"UpcomingMatches": sparseGameWeeks(sortedUpcomingWeeks, fields),
+		}
+	}
+	if wantsEnvelope(c) {
+		respondEnvelope(c, data, len(schedules))
 	} else {
-		c.JSON(http.StatusOK, scheduleData)
+		respondJSON(c, http.StatusOK, data)
+	}
+}
+
+// sortGameWeeks orders game weeks by their number, descending if asked
+func sortGameWeeks(weeks []GameWeek, descending bool) {
+	sort.Slice(weeks, func(i, j int) bool {
+		if descending {
+			return weeks[i].Week > weeks[j].Week
+		}
+		return weeks[i].Week < weeks[j].Week
+	})
+}
+
+// downloadSchedule serves the schedule JSON as a file download
+func downloadSchedule(c *gin.Context) {
+	c.Header("Content-Disposition", `attachment; filename="schedule.json"`)
+	getScheduleData(c)
+}
+
+// sparseGameWeeks reduces the matches of each game week to the given fields
+func sparseGameWeeks(weeks []GameWeek, fields []string) []gin.H {
+	sparse := make([]gin.H, 0, len(weeks))
+	for _, week := range weeks {
+		matches := make([]map[string]interface{}, 0, len(week.Matches))
+		for _, match := range week.Matches {
+			matches = append(matches, selectFields(match, fields))
+		}
+		sparse = append(sparse, gin.H{"Week": week.Week, "Matches": matches})
+	}
+	return sparse
+}
+
+// GameDetail is a single game with the result derived from its scores
+type GameDetail struct {
+	Schedule
+	Winner string `json:"winner"`           // home, away, tie or unplayed
+	Margin *int   `json:"margin,omitempty"` // Absolute score difference, nil if unplayed
+}
+
+// newGameDetail derives the winner and margin of a game as of now
+func newGameDetail(s Schedule, now time.Time) GameDetail {
+	return gameDetail(s, gamePlayed(s, now))
+}
+
+// gameDetail derives the winner and margin of a game whose played state is
+// already known, e.g. a display row whose date has been reformatted
+func gameDetail(s Schedule, played bool) GameDetail {
+	detail := GameDetail{Schedule: s}
+	if !played {
+		detail.Winner = "unplayed"
+		return detail
+	}
+
+	margin := s.HomeScore - s.AwayScore
+	switch {
+	case margin > 0:
+		detail.Winner = "home"
+	case margin < 0:
+		detail.Winner = "away"
+		margin = -margin
+	default:
+		detail.Winner = "tie"
+	}
+	detail.Margin = &margin
+	return detail
+}
+
+func getGame(c *gin.Context) {
+	s, err := scanSchedule(db.QueryRowContext(c.Request.Context(), "SELECT "+scheduleColumns+" FROM schedule WHERE statcrew_id = ?", c.Param("id")))
+	if err == sql.ErrNoRows {
+		respondError(c, http.StatusNotFound, codeNotFound, "game not found")
+		return
 	}
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	// Add team logos
+	s.HomeLogo = teamLogos[s.HomeTeam]
+	s.AwayLogo = teamLogos[s.AwayTeam]
+	now := time.Now()
+	s.Status = gameStatus(s, now)
+
+	c.JSON(http.StatusOK, newGameDetail(s, now))
 }
 
 type TeamStanding struct {
-	TeamName      string
-	Division      string
-	Wins          int
-	Losses        int
-	Record        string
-	Position      int
-	SoS           float64 // Strength of Schedule
-	SoV           float64 // Strength of Victory
-	Logo          string  // Team logo filename
-	PointsFor     int     // PF - Points scored
-	PointsAgainst int     // PA - Points allowed
-	PointDiff     int     // PD - Point differential
-	DivWins       int     // Division wins
-	DivLosses     int     // Division losses
-	DivRecord     string  // Division record
+	TeamName       string
+	Division       string
+	Wins           int
+	Losses         int
+	Record         string
+	Position       int
+	SoS            float64 // Strength of Schedule
+	SoV            float64 // Strength of Victory
+	Logo           string  // Team logo filename
+	PointsFor      int     // PF - Points scored
+	PointsAgainst  int     // PA - Points allowed
+	PointDiff      int     // PD - Point differential
+	DivWins        int     // Division wins
+	DivLosses      int     // Division losses
+	DivRecord      string  // Division record
+	Clinched       bool    // Clinched the division
+	Eliminated     bool    // Can no longer win the division
+	GamesPlayed    int     // Games with a result
+	GamesRemaining int     // Regular season games left (GOELF_GAMES_PER_TEAM minus played)
+	Form           string  // Results of the last games, oldest first (e.g. "WWLWT")
+	Streak         string  // Current run of identical results (e.g. "W3"), empty before the first game
+	Favorite       bool    `json:"favorite,omitempty"` // One of the requested favorite teams
+	Color          string  `json:",omitempty"`         // Primary team color from the teams table
+}
+
+// isKnownDivision reports whether the (upper case) division name exists
+func isKnownDivision(division string) bool {
+	for _, d := range config.Divisions {
+		if d == division {
+			return true
+		}
+	}
+	return false
 }
 
 // Division mapping
@@ -547,135 +1553,212 @@ var teamLogos = map[string]string{
 	"Helvetic Mercenaries": "hvm.png",
 }
 
+// standingsCutoff parses ?asOf=YYYY-MM-DD or ?throughWeek=N into a filter
+// for the games counted in the standings. It returns nil for the current
+// standings.
+func standingsCutoff(c *gin.Context) (func(Schedule) bool, error) {
+	asOf, throughWeek := c.Query("asOf"), c.Query("throughWeek")
+	switch {
+	case asOf != "" && throughWeek != "":
+		return nil, fmt.Errorf("use either asOf or throughWeek, not both")
+	case asOf != "":
+		date, err := time.Parse("2006-01-02", asOf)
+		if err != nil {
+			return nil, fmt.Errorf("asOf must be a date like 2025-06-30")
+		}
+		cutoff := date.Format("2006-01-02")
+		return func(game Schedule) bool {
+			return len(game.Date) >= 10 && game.Date[:10] <= cutoff
+		}, nil
+	case throughWeek != "":
+		week, err := strconv.Atoi(throughWeek)
+		if err != nil || week < 1 {
+			return nil, fmt.Errorf("throughWeek must be a positive number")
+		}
+		return func(game Schedule) bool {
+			return game.GameWeek <= week
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
 func getScoreboard(c *gin.Context) {
-	// Get all schedule data to calculate standings
-	rows, err := db.Query("SELECT home_team, away_team, home_score, away_score FROM schedule WHERE home_score > 0 OR away_score > 0 ORDER BY date, time")
+	// Standings at an earlier point of the season are computed on demand
+	cutoff, err := standingsCutoff(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, codeBadRequest, err.Error())
 		return
 	}
-	defer rows.Close()
 
-	// Store all games for SoS/SoV calculations
-	var games []struct {
-		homeTeam  string
-		awayTeam  string
-		homeScore int
-		awayScore int
-	}
-
-	// Calculate standings from schedule data
-	teamStats := make(map[string]struct {
-		wins          int
-		losses        int
-		pointsFor     int
-		pointsAgainst int
-		divWins       int
-		divLosses     int
-	})
+	var standings []DivisionData
+	if cutoff != nil {
+		standings, err = getStandingsWhere(c.Request.Context(), cutoff)
+	} else {
+		standings, err = getStandings(c.Request.Context())
+	}
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	// Optionally only return a single division
+	if division := c.Query("division"); division != "" {
+		division = strings.ToUpper(division)
+		if !hasDivision(divisionMap(leagueFromContext(c.Request.Context())), division) {
+			respondError(c, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("unknown division %q", c.Query("division")))
+			return
+		}
+
+		filtered := []DivisionData{}
+		for _, data := range standings {
+			if data.Division == division {
+				filtered = append(filtered, data)
+			}
+		}
+		standings = filtered
+	}
+	standings = markFavoriteStandings(standings, requestFavorites(c))
+
+	// Check if request is from HTMX (has HX-Request header)
+	if wantsHTML(c) {
+		c.HTML(http.StatusOK, "scoreboard.html", standings)
+	} else if negotiateFormat(c, mimeJSON, mimeCSV) == mimeCSV {
+		getScoreboardCSV(c, standings)
+	} else if wantsEnvelope(c) {
+		count := 0
+		for _, division := range standings {
+			count += len(division.Teams)
+		}
+		respondEnvelope(c, standings, count)
+	} else {
+		respondJSON(c, http.StatusOK, standings)
+	}
+}
+
+// recomputeStandings drops the cached standings and computes them again from
+// the stored schedule, without fetching from the upstream
+func recomputeStandings(c *gin.Context) {
+	invalidateStandings()
+
+	standings, err := getStandings(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	logInfof("Standings recomputed")
+	c.JSON(http.StatusOK, standings)
+}
+
+// DivisionData groups the sorted standings of a single division
+type DivisionData struct {
+	Division string
+	Teams    []TeamStanding
+}
+
+// teamRecord accumulates the raw per-team numbers while walking the games
+type teamRecord struct {
+	games         int
+	wins          int
+	losses        int
+	pointsFor     int
+	pointsAgainst int
+	divWins       int
+	divLosses     int
+}
 
+// isPlayed reports whether a game has a result. The upstream reports 0-0 for
+// games that haven't been played yet, so any score counts as played.
+func isPlayed(homeScore, awayScore int) bool {
+	return homeScore > 0 || awayScore > 0
+}
+
+// loadSchedule reads all games from the database, ordered by date and time
+func loadSchedule(ctx context.Context) ([]Schedule, error) {
+	rows, err := db.QueryContext(ctx, "SELECT "+scheduleColumns+" FROM schedule WHERE league = ? ORDER BY date, time", leagueFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []Schedule
 	for rows.Next() {
-		var homeTeam, awayTeam string
-		var homeScore, awayScore int
-		err := rows.Scan(&homeTeam, &awayTeam, &homeScore, &awayScore)
+		s, err := scanSchedule(rows)
 		if err != nil {
-			log.Printf("Error scanning schedule: %v", err)
+			logErrorf("Error scanning schedule: %v", err)
 			continue
 		}
+		games = append(games, s)
+	}
+	return games, rows.Err()
+}
 
-		// Only count games that have been played (scores > 0)
-		if homeScore > 0 || awayScore > 0 {
-			// Store game for SoS/SoV calculations
-			games = append(games, struct {
-				homeTeam  string
-				awayTeam  string
-				homeScore int
-				awayScore int
-			}{homeTeam, awayTeam, homeScore, awayScore})
-
-			if homeScore > awayScore {
-				// Home team wins
-				homeDivWin := 0
-				awayDivLoss := 0
-				if teamDivisions[homeTeam] == teamDivisions[awayTeam] {
-					homeDivWin = 1
-					awayDivLoss = 1
-				}
-
-				teamStats[homeTeam] = struct {
-					wins          int
-					losses        int
-					pointsFor     int
-					pointsAgainst int
-					divWins       int
-					divLosses     int
-				}{
-					teamStats[homeTeam].wins + 1,
-					teamStats[homeTeam].losses,
-					teamStats[homeTeam].pointsFor + homeScore,
-					teamStats[homeTeam].pointsAgainst + awayScore,
-					teamStats[homeTeam].divWins + homeDivWin,
-					teamStats[homeTeam].divLosses,
-				}
-				teamStats[awayTeam] = struct {
-					wins          int
-					losses        int
-					pointsFor     int
-					pointsAgainst int
-					divWins       int
-					divLosses     int
-				}{
-					teamStats[awayTeam].wins,
-					teamStats[awayTeam].losses + 1,
-					teamStats[awayTeam].pointsFor + awayScore,
-					teamStats[awayTeam].pointsAgainst + homeScore,
-					teamStats[awayTeam].divWins,
-					teamStats[awayTeam].divLosses + awayDivLoss,
-				}
-			} else if awayScore > homeScore {
-				// Away team wins
-				awayDivWin := 0
-				homeDivLoss := 0
-				if teamDivisions[homeTeam] == teamDivisions[awayTeam] {
-					awayDivWin = 1
-					homeDivLoss = 1
-				}
-
-				teamStats[awayTeam] = struct {
-					wins          int
-					losses        int
-					pointsFor     int
-					pointsAgainst int
-					divWins       int
-					divLosses     int
-				}{
-					teamStats[awayTeam].wins + 1,
-					teamStats[awayTeam].losses,
-					teamStats[awayTeam].pointsFor + awayScore,
-					teamStats[awayTeam].pointsAgainst + homeScore,
-					teamStats[awayTeam].divWins + awayDivWin,
-					teamStats[awayTeam].divLosses,
-				}
-				teamStats[homeTeam] = struct {
-					wins          int
-					losses        int
-					pointsFor     int
-					pointsAgainst int
-					divWins       int
-					divLosses     int
-				}{
-					teamStats[homeTeam].wins,
-					teamStats[homeTeam].losses + 1,
-					teamStats[homeTeam].pointsFor + homeScore,
-					teamStats[homeTeam].pointsAgainst + awayScore,
-					teamStats[homeTeam].divWins,
-					teamStats[homeTeam].divLosses + homeDivLoss,
-				}
-			}
+// mergeScoreboard fills in the scores of games the schedule has no result for
+// from the scoreboard table. The schedule always wins on conflict: once it
+// reports a result, the scoreboard scores for that game are ignored.
+func mergeScoreboard(ctx context.Context, games []Schedule) error {
+	rows, err := db.QueryContext(ctx, "SELECT statcrew_id, home_score, away_score FROM scoreboard")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	scores := make(map[string][2]int)
+	for rows.Next() {
+		var id, homeScore, awayScore string
+		if err := rows.Scan(&id, &homeScore, &awayScore); err != nil {
+			logErrorf("Error scanning scoreboard: %v", err)
+			continue
+		}
+		// Rows stored by older versions may hold the upstream's text, e.g. ""
+		// for games without a score
+		home, homeErr := strconv.Atoi(homeScore)
+		away, awayErr := strconv.Atoi(awayScore)
+		if homeErr != nil || awayErr != nil {
+			continue
+		}
+		scores[id] = [2]int{home, away}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i, game := range games {
+		if isPlayed(game.HomeScore, game.AwayScore) {
+			continue
+		}
+		if score, ok := scores[game.StatcrewID]; ok {
+			games[i].HomeScore = score[0]
+			games[i].AwayScore = score[1]
+		}
+	}
+	return nil
+}
+
+// computeStandings calculates the division standings from the given games,
+// grouping the teams by the league's division map. Games without a result
+// are ignored.
+func computeStandings(games []Schedule, divisions map[string]string) []DivisionData {
+	played := playedGames(games)
+	teamStats, headToHead, results := tallyGames(played, divisions)
+
+	// Teams that haven't played yet are listed at 0-0, their SoS and SoV
+	// stay 0 as they have no opponents
+	if config.StandingsAllTeams {
+		for _, team := range idleTeams(games, teamStats, divisions) {
+			teamStats[team] = teamRecord{}
 		}
 	}
 
 	// Calculate SoS and SoV for each team
+	//
+	// SoS = sum(opponent wins) / sum(opponent games), over every game played
+	// SoV = the same, but only over the games the team won
+	//
+	// Each opponent's record excludes its games against the team itself, so a
+	// team's own results don't feed into its strength of schedule. Opponents
+	// met more than once are counted once per game.
 	teamSoS := make(map[string]float64)
 	teamSoV := make(map[string]float64)
 
@@ -688,30 +1771,30 @@ func getScoreboard(c *gin.Context) {
 		defeatedOpponentWins := 0
 		defeatedOpponentLosses := 0
 
-		for _, game := range games {
+		for _, game := range played {
 			// Check if this team played in this game
-			if game.homeTeam == teamName {
+			var opponent string
+			var won bool
+			if game.HomeTeam == teamName {
 				// Team was home team
-				opponent := game.awayTeam
-				opponentWins += teamStats[opponent].wins
-				opponentLosses += teamStats[opponent].losses
-
-				// If team won, add opponent stats to SoV
-				if game.homeScore > game.awayScore {
-					defeatedOpponentWins += teamStats[opponent].wins
-					defeatedOpponentLosses += teamStats[opponent].losses
-				}
-			} else if game.awayTeam == teamName {
+				opponent = game.AwayTeam
+				won = game.HomeScore > game.AwayScore
+			} else if game.AwayTeam == teamName {
 				// Team was away team
-				opponent := game.homeTeam
-				opponentWins += teamStats[opponent].wins
-				opponentLosses += teamStats[opponent].losses
-
-				// If team won, add opponent stats to SoV
-				if game.awayScore > game.homeScore {
-					defeatedOpponentWins += teamStats[opponent].wins
-					defeatedOpponentLosses += teamStats[opponent].losses
-				}
+				opponent = game.HomeTeam
+				won = game.AwayScore > game.HomeScore
+			} else {
+				continue
+			}
+
+			wins, losses := opponentRecord(teamStats, headToHead, teamName, opponent)
+			opponentWins += wins
+			opponentLosses += losses
+
+			// If team won, add opponent stats to SoV
+			if won {
+				defeatedOpponentWins += wins
+				defeatedOpponentLosses += losses
 			}
 		}
 
@@ -736,56 +1819,54 @@ func getScoreboard(c *gin.Context) {
 	divisionStandings := make(map[string][]TeamStanding)
 
 	for teamName, stats := range teamStats {
-		division := teamDivisions[teamName]
+		division := divisions[teamName]
 		if division == "" {
 			division = "UNKNOWN" // Fallback for any unmapped teams
 		}
 
 		standing := TeamStanding{
-			TeamName:      teamName,
-			Division:      division,
-			Wins:          stats.wins,
-			Losses:        stats.losses,
-			Record:        fmt.Sprintf("%d-%d", stats.wins, stats.losses),
-			SoS:           teamSoS[teamName],
-			SoV:           teamSoV[teamName],
-			Logo:          teamLogos[teamName],
-			PointsFor:     stats.pointsFor,
-			PointsAgainst: stats.pointsAgainst,
-			PointDiff:     stats.pointsFor - stats.pointsAgainst,
-			DivWins:       stats.divWins,
-			DivLosses:     stats.divLosses,
-			DivRecord:     fmt.Sprintf("%d-%d", stats.divWins, stats.divLosses),
+			TeamName:       teamName,
+			Division:       division,
+			Wins:           stats.wins,
+			Losses:         stats.losses,
+			Record:         fmt.Sprintf("%d-%d", stats.wins, stats.losses),
+			SoS:            teamSoS[teamName],
+			SoV:            teamSoV[teamName],
+			Logo:           teamLogos[teamName],
+			Color:          teamColors[teamName],
+			PointsFor:      stats.pointsFor,
+			PointsAgainst:  stats.pointsAgainst,
+			PointDiff:      stats.pointsFor - stats.pointsAgainst,
+			DivWins:        stats.divWins,
+			DivLosses:      stats.divLosses,
+			DivRecord:      fmt.Sprintf("%d-%d", stats.divWins, stats.divLosses),
+			GamesPlayed:    stats.games,
+			GamesRemaining: gamesRemaining(stats.games),
+			Form:           recentForm(results[teamName], config.FormGames),
+			Streak:         currentStreak(results[teamName]),
 		}
 
 		divisionStandings[division] = append(divisionStandings[division], standing)
 	}
 
-	// Sort each division by wins (descending), then by losses (ascending)
+	// Sort each division using the standings tiebreakers
 	for division := range divisionStandings {
-		sort.Slice(divisionStandings[division], func(i, j int) bool {
-			if divisionStandings[division][i].Wins != divisionStandings[division][j].Wins {
-				return divisionStandings[division][i].Wins > divisionStandings[division][j].Wins
-			}
-			return divisionStandings[division][i].Losses < divisionStandings[division][j].Losses
+		teams := divisionStandings[division]
+		sort.Slice(teams, func(i, j int) bool {
+			return rankBefore(teams[i], teams[j])
 		})
 
 		// Add position numbers within each division
-		for i := range divisionStandings[division] {
-			divisionStandings[division][i].Position = i + 1
+		for i := range teams {
+			teams[i].Position = i + 1
 		}
-	}
 
-	// Create final standings structure
-	type DivisionData struct {
-		Division string
-		Teams    []TeamStanding
+		markClinchStatus(teams)
 	}
 
+	// Create final standings structure
 	var standings []DivisionData
-	divisions := []string{"EAST", "WEST", "NORTH", "SOUTH"}
-
-	for _, division := range divisions {
+	for _, division := range divisionOrder(divisions) {
 		if teams, exists := divisionStandings[division]; exists {
 			standings = append(standings, DivisionData{
 				Division: division,
@@ -794,20 +1875,197 @@ func getScoreboard(c *gin.Context) {
 		}
 	}
 
-	// Check if request is from HTMX (has HX-Request header)
-	if c.GetHeader("HX-Request") == "true" {
-		c.HTML(http.StatusOK, "scoreboard.html", standings)
-	} else {
-		c.JSON(http.StatusOK, standings)
+	return standings
+}
+
+// playedGames returns the games that count as played, oldest first
+func playedGames(games []Schedule) []Schedule {
+	return playedGamesAt(games, time.Now())
+}
+
+// playedGamesAt is playedGames as of now
+func playedGamesAt(games []Schedule, now time.Time) []Schedule {
+	var played []Schedule
+	for _, game := range games {
+		if gamePlayed(game, now) {
+			played = append(played, game)
+		}
+	}
+	sortGamesByDate(played)
+	return played
+}
+
+// tallyGames accumulates the records of the played games: per team, per
+// (team, opponent) pair from the opponent's view, and each team's results
+// in chronological order. Division records follow the division map.
+func tallyGames(played []Schedule, divisions map[string]string) (map[string]teamRecord, map[[2]string]teamRecord, map[string][]byte) {
+	// Results of each team in chronological order
+	results := make(map[string][]byte)
+
+	// Calculate records and points from the played games
+	teamStats := make(map[string]teamRecord)
+	headToHead := make(map[[2]string]teamRecord)
+
+	for _, game := range played {
+		home := teamStats[game.HomeTeam]
+		away := teamStats[game.AwayTeam]
+
+		home.games++
+		away.games++
+		home.pointsFor += game.HomeScore
+		home.pointsAgainst += game.AwayScore
+		away.pointsFor += game.AwayScore
+		away.pointsAgainst += game.HomeScore
+
+		divisionGame := divisions[game.HomeTeam] == divisions[game.AwayTeam]
+
+		// Head-to-head records, keyed by (team, opponent) from the opponent's view
+		homeVsAway := headToHead[[2]string{game.AwayTeam, game.HomeTeam}]
+		awayVsHome := headToHead[[2]string{game.HomeTeam, game.AwayTeam}]
+
+		homeResult, awayResult := byte('T'), byte('T')
+		if game.HomeScore > game.AwayScore {
+			// Home team wins
+			home.wins++
+			away.losses++
+			homeVsAway.wins++
+			awayVsHome.losses++
+			homeResult, awayResult = 'W', 'L'
+			if divisionGame {
+				home.divWins++
+				away.divLosses++
+			}
+		} else if game.AwayScore > game.HomeScore {
+			// Away team wins
+			away.wins++
+			home.losses++
+			awayVsHome.wins++
+			homeVsAway.losses++
+			homeResult, awayResult = 'L', 'W'
+			if divisionGame {
+				away.divWins++
+				home.divLosses++
+			}
+		}
+		results[game.HomeTeam] = append(results[game.HomeTeam], homeResult)
+		results[game.AwayTeam] = append(results[game.AwayTeam], awayResult)
+
+		teamStats[game.HomeTeam] = home
+		teamStats[game.AwayTeam] = away
+		headToHead[[2]string{game.AwayTeam, game.HomeTeam}] = homeVsAway
+		headToHead[[2]string{game.HomeTeam, game.AwayTeam}] = awayVsHome
+	}
+	return teamStats, headToHead, results
+}
+
+// opponentRecord returns the record of an opponent without its games against
+// the given team
+func opponentRecord(teamStats map[string]teamRecord, headToHead map[[2]string]teamRecord, team, opponent string) (int, int) {
+	vsTeam := headToHead[[2]string{team, opponent}]
+	return teamStats[opponent].wins - vsTeam.wins, teamStats[opponent].losses - vsTeam.losses
+}
+
+// recentForm returns the last n results, oldest first
+func recentForm(results []byte, n int) string {
+	if len(results) > n {
+		results = results[len(results)-n:]
+	}
+	return string(results)
+}
+
+// currentStreak returns the latest result and how many games in a row it
+// has occurred, e.g. "W3". A tie ends a win or loss streak.
+func currentStreak(results []byte) string {
+	if len(results) == 0 {
+		return ""
+	}
+	last := results[len(results)-1]
+	count := 0
+	for i := len(results) - 1; i >= 0 && results[i] == last; i-- {
+		count++
+	}
+	return fmt.Sprintf("%c%d", last, count)
+}
+
+// gamesRemaining returns the regular season games left after the given
+// number of played games
+func gamesRemaining(played int) int {
+	if remaining := config.GamesPerTeam - played; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// markClinchStatus flags the teams of a single division that have clinched
+// it or can no longer win it. A team has clinched when no other team in the
+// division can reach its win total with their remaining games, and a team is
+// eliminated when it can't reach the current leader's win total anymore.
+func markClinchStatus(teams []TeamStanding) {
+	if len(teams) == 0 {
+		return
+	}
+
+	mostWins := 0
+	for _, team := range teams {
+		if team.Wins > mostWins {
+			mostWins = team.Wins
+		}
+	}
+
+	for i := range teams {
+		maxWins := teams[i].Wins + teams[i].GamesRemaining
+		teams[i].Eliminated = maxWins < mostWins
+
+		clinched := true
+		for j, other := range teams {
+			if i != j && other.Wins+other.GamesRemaining >= teams[i].Wins {
+				clinched = false
+				break
+			}
+		}
+		teams[i].Clinched = clinched
+	}
+}
+
+// rankBefore orders two teams by wins (descending), losses (ascending),
+// then SoV, SoS and point differential (all descending), falling back to
+// the team name so the order is stable
+func rankBefore(a, b TeamStanding) bool {
+	if a.Wins != b.Wins {
+		return a.Wins > b.Wins
+	}
+	if a.Losses != b.Losses {
+		return a.Losses < b.Losses
+	}
+	if a.SoV != b.SoV {
+		return a.SoV > b.SoV
 	}
+	if a.SoS != b.SoS {
+		return a.SoS > b.SoS
+	}
+	if a.PointDiff != b.PointDiff {
+		return a.PointDiff > b.PointDiff
+	}
+	return a.TeamName < b.TeamName
 }
 
 type PlayoffBracket struct {
+	Seeds         []PlayoffSeed
 	WildcardRound []PlayoffGame
 	SemiFinals    []PlayoffGame
 	Championship  PlayoffGame
 }
 
+// PlayoffSeed is a team that qualified for the playoffs
+type PlayoffSeed struct {
+	Seed           int
+	TeamName       string
+	Division       string
+	Record         string
+	DivisionWinner bool
+	Logo           string
+}
+
 type PlayoffGame struct {
 	Team1    string
 	Team2    string
@@ -820,148 +2078,151 @@ type PlayoffGame struct {
 }
 
 func getPlayoffs(c *gin.Context) {
-	// Get all teams and their standings
-	rows, err := db.Query("SELECT home_team, away_team, home_score, away_score FROM schedule WHERE home_score > 0 OR away_score > 0 ORDER BY date, time")
+	standings, err := getStandings(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDBError(c, err)
 		return
 	}
-	defer rows.Close()
 
-	// Calculate standings (same logic as getScoreboard)
-	teamStats := make(map[string]struct {
-		wins   int
-		losses int
-	})
+	seeds := computePlayoffSeeds(standings, config.PlayoffTeams, config.PlayoffWildcards)
+	bracket := buildPlayoffBracket(seeds)
 
-	for rows.Next() {
-		var homeTeam, awayTeam string
-		var homeScore, awayScore int
-		err := rows.Scan(&homeTeam, &awayTeam, &homeScore, &awayScore)
-		if err != nil {
-			log.Printf("Error scanning schedule: %v", err)
-			continue
-		}
+	// Check if request is from HTMX
+	if wantsHTML(c) {
+		c.HTML(http.StatusOK, "playoffs.html", bracket)
+	} else {
+		c.JSON(http.StatusOK, bracket)
+	}
+}
 
-		if homeScore > 0 || awayScore > 0 {
-			if homeScore > awayScore {
-				teamStats[homeTeam] = struct {
-					wins   int
-					losses int
-				}{teamStats[homeTeam].wins + 1, teamStats[homeTeam].losses}
-				teamStats[awayTeam] = struct {
-					wins   int
-					losses int
-				}{teamStats[awayTeam].wins, teamStats[awayTeam].losses + 1}
-			} else if awayScore > homeScore {
-				teamStats[awayTeam] = struct {
-					wins   int
-					losses int
-				}{teamStats[awayTeam].wins + 1, teamStats[awayTeam].losses}
-				teamStats[homeTeam] = struct {
-					wins   int
-					losses int
-				}{teamStats[homeTeam].wins, teamStats[homeTeam].losses + 1}
+// computePlayoffSeeds picks the playoff teams from the standings. Division
+// winners take the first seeds, the remaining spots go to the best other
+// teams as wildcards. If there are more division winners than automatic
+// spots, the weaker division winners compete for the wildcards.
+func computePlayoffSeeds(standings []DivisionData, teams, wildcards int) []PlayoffSeed {
+	var winners, others []TeamStanding
+	for _, division := range standings {
+		for _, team := range division.Teams {
+			if team.Position == 1 {
+				winners = append(winners, team)
+			} else {
+				others = append(others, team)
 			}
 		}
 	}
 
-	// Create standings for all teams
-	var allTeams []struct {
-		teamName string
-		division string
-		wins     int
-		losses   int
+	sort.Slice(winners, func(i, j int) bool { return rankBefore(winners[i], winners[j]) })
+
+	automatic := teams - wildcards
+	if automatic < len(winners) {
+		others = append(others, winners[automatic:]...)
+		winners = winners[:automatic]
 	}
 
-	for teamName, stats := range teamStats {
-		division := teamDivisions[teamName]
-		if division == "" {
-			division = "UNKNOWN"
-		}
+	sort.Slice(others, func(i, j int) bool { return rankBefore(others[i], others[j]) })
 
-		allTeams = append(allTeams, struct {
-			teamName string
-			division string
-			wins     int
-			losses   int
-		}{teamName, division, stats.wins, stats.losses})
+	var seeds []PlayoffSeed
+	addSeed := func(team TeamStanding, divisionWinner bool) {
+		seeds = append(seeds, PlayoffSeed{
+			Seed:           len(seeds) + 1,
+			TeamName:       team.TeamName,
+			Division:       team.Division,
+			Record:         team.Record,
+			DivisionWinner: divisionWinner,
+			Logo:           team.Logo,
+		})
 	}
 
-	// Sort all teams by wins (descending), then by losses (ascending)
-	sort.Slice(allTeams, func(i, j int) bool {
-		if allTeams[i].wins != allTeams[j].wins {
-			return allTeams[i].wins > allTeams[j].wins
+	for _, team := range winners {
+		addSeed(team, true)
+	}
+	for _, team := range others {
+		if len(seeds) >= teams {
+			break
 		}
-		return allTeams[i].losses < allTeams[j].losses
-	})
+		addSeed(team, false)
+	}
 
-	// Get division champions (top team from each division)
-	divisionChampions := make(map[string]string)
-	for _, team := range allTeams {
-		if _, exists := divisionChampions[team.division]; !exists {
-			divisionChampions[team.division] = team.teamName
-		}
+	return seeds
+}
+
+// bracketOrder returns the seeds of a bracket with the given size (a power
+// of two) in slot order, so that the top seeds meet as late as possible
+func bracketOrder(size int) []int {
+	if size <= 2 {
+		return []int{1, 2}
+	}
+	var order []int
+	for _, seed := range bracketOrder(size / 2) {
+		order = append(order, seed, size+1-seed)
 	}
+	return order
+}
 
-	// Create seeds 1-4 from division champions
-	var seeds []string
-	divisions := []string{"EAST", "WEST", "NORTH", "SOUTH"}
-	for _, division := range divisions {
-		if champion, exists := divisionChampions[division]; exists {
-			seeds = append(seeds, champion)
-		}
+// buildPlayoffBracket builds the bracket for the seeded teams. Brackets that
+// aren't full give byes to the top seeds, so with six teams seeds 1 and 2
+// skip the wildcard round.
+func buildPlayoffBracket(seeds []PlayoffSeed) PlayoffBracket {
+	bracket := PlayoffBracket{Seeds: seeds}
+	if len(seeds) < 2 {
+		bracket.Championship = PlayoffGame{Team1: "TBD", Team2: "TBD"}
+		return bracket
 	}
 
-	// Sort division champions by overall record for seeds 1-4
-	sort.Slice(seeds, func(i, j int) bool {
-		team1Stats := teamStats[seeds[i]]
-		team2Stats := teamStats[seeds[j]]
-		if team1Stats.wins != team2Stats.wins {
-			return team1Stats.wins > team2Stats.wins
-		}
-		return team1Stats.losses < team2Stats.losses
-	})
+	size := 2
+	for size < len(seeds) {
+		size *= 2
+	}
 
-	// Get wildcard teams (best 2 non-division champions)
-	var wildcardTeams []string
-	for _, team := range allTeams {
-		isChampion := false
-		for _, champion := range seeds {
-			if team.teamName == champion {
-				isChampion = true
-				break
-			}
-		}
-		if !isChampion {
-			wildcardTeams = append(wildcardTeams, team.teamName)
+	// A slot holds a seed, or 0 if the team is still to be determined
+	slots := bracketOrder(size)
+	for i, seed := range slots {
+		if seed > len(seeds) {
+			slots[i] = -1 // Bye
 		}
 	}
 
-	// Take top 2 wildcard teams
-	if len(wildcardTeams) > 2 {
-		wildcardTeams = wildcardTeams[:2]
+	slotGame := func(seed1, seed2 int) PlayoffGame {
+		game := PlayoffGame{Team1: "TBD", Team2: "TBD", Seed1: seed1, Seed2: seed2}
+		if seed1 > 0 {
+			game.Team1 = seeds[seed1-1].TeamName
+			game.Logo1 = seeds[seed1-1].Logo
+		}
+		if seed2 > 0 {
+			game.Team2 = seeds[seed2-1].TeamName
+			game.Logo2 = seeds[seed2-1].Logo
+		}
+		return game
+	}
+
+	var rounds [][]PlayoffGame
+	for len(slots) > 1 {
+		var round []PlayoffGame
+		var next []int
+		for i := 0; i < len(slots); i += 2 {
+			seed1, seed2 := slots[i], slots[i+1]
+			switch {
+			case seed2 == -1:
+				next = append(next, seed1)
+			case seed1 == -1:
+				next = append(next, seed2)
+			default:
+				round = append(round, slotGame(seed1, seed2))
+				next = append(next, 0)
+			}
+		}
+		rounds = append(rounds, round)
+		slots = next
 	}
 
-	// Create playoff bracket
-	bracket := PlayoffBracket{
-		WildcardRound: []PlayoffGame{
-			{Team1: seeds[2], Team2: wildcardTeams[1], Seed1: 3, Seed2: 6, IsPlayed: false, Logo1: teamLogos[seeds[2]], Logo2: teamLogos[wildcardTeams[1]]}, // Seed 3 vs Seed 6
-			{Team1: seeds[3], Team2: wildcardTeams[0], Seed1: 4, Seed2: 5, IsPlayed: false, Logo1: teamLogos[seeds[3]], Logo2: teamLogos[wildcardTeams[0]]}, // Seed 4 vs Seed 5
-		},
-		SemiFinals: []PlayoffGame{
-			{Team1: seeds[0], Team2: "TBD", Seed1: 1, Seed2: 0, IsPlayed: false, Logo1: teamLogos[seeds[0]], Logo2: ""}, // Seed 1 vs TBD
-			{Team1: seeds[1], Team2: "TBD", Seed1: 2, Seed2: 0, IsPlayed: false, Logo1: teamLogos[seeds[1]], Logo2: ""}, // Seed 2 vs TBD
-		},
-		Championship: PlayoffGame{Team1: "TBD", Team2: "TBD", IsPlayed: false, Logo1: "", Logo2: ""},
+	bracket.Championship = rounds[len(rounds)-1][0]
+	if len(rounds) >= 2 {
+		bracket.SemiFinals = rounds[len(rounds)-2]
 	}
-
-	// Check if request is from HTMX
-	if c.GetHeader("HX-Request") == "true" {
-		c.HTML(http.StatusOK, "playoffs.html", bracket)
-	} else {
-		c.JSON(http.StatusOK, bracket)
+	if len(rounds) >= 3 {
+		bracket.WildcardRound = rounds[len(rounds)-3]
 	}
+	return bracket
 }
 
 func getTeamName(statcrewID string) string {
@@ -982,78 +2243,184 @@ func getTeamName(statcrewID string) string {
 	return statcrewID
 }
 
-func refreshData(c *gin.Context) {
-	go func() {
-		fetchSchedule()
-		// Scoreboard is calculated from schedule data
-	}()
+// mockGame builds a mock schedule entry. Unplayed games are passed with 0-0.
+func mockGame(id string, week int, date, kickoff, home, away, location string, homeScore, awayScore int) Schedule {
+	return Schedule{
+		StatcrewID: id,
+		HomeTeam:   home,
+		AwayTeam:   away,
+		Date:       date + "T" + kickoff + ":00.000Z",
+		Time:       kickoff + ":00",
+		GameWeek:   week,
+		Location:   location,
+		HomeScore:  homeScore,
+		AwayScore:  awayScore,
+		Slug:       id,
+		GameDate:   date + "T" + kickoff + ":00",
+	}
+}
 
-	// Check if request is from HTMX (has HX-Request header)
-	if c.GetHeader("HX-Request") == "true" {
-		c.HTML(http.StatusOK, "refresh.html", gin.H{"message": "Data refresh initiated"})
-	} else {
-		c.JSON(http.StatusOK, gin.H{"message": "Data refresh initiated"})
+// mockSchedules returns a small ELF season: three played weeks and an
+// upcoming week that starts the day after now, so the season always looks
+// current
+func mockSchedules(now time.Time) []Schedule {
+	day := func(offset int) string {
+		return now.AddDate(0, 0, offset).Format("2006-01-02")
+	}
+	return []Schedule{
+		// Week 1
+		mockGame("mock-w1-1", 1, day(-20), "15:00", "Vienna Vikings", "Prague Lions", "Vienna", 31, 17),
+		mockGame("mock-w1-2", 1, day(-20), "18:00", "Stuttgart Surge", "Cologne Centurions", "Stuttgart", 27, 24),
+		mockGame("mock-w1-3", 1, day(-19), "13:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 38, 14),
+		mockGame("mock-w1-4", 1, day(-19), "15:00", "Munich Ravens", "Raiders Tirol", "Munich", 21, 20),
+		mockGame("mock-w1-5", 1, day(-19), "15:00", "Wroclaw Panthers", "Fehervar Enthroners", "Wroclaw", 24, 10),
+		mockGame("mock-w1-6", 1, day(-19), "16:00", "Paris Musketeers", "Frankfurt Galaxy", "Paris", 17, 28),
+		mockGame("mock-w1-7", 1, day(-19), "17:00", "Hamburg Sea Devils", "Nordic Storm", "Hamburg", 35, 21),
+		mockGame("mock-w1-8", 1, day(-19), "18:00", "Madrid Bravos", "Helvetic Mercenaries", "Madrid", 14, 23),
+		// Week 2
+		mockGame("mock-w2-1", 2, day(-13), "15:00", "Prague Lions", "Wroclaw Panthers", "Prague", 20, 27),
+		mockGame("mock-w2-2", 2, day(-13), "18:00", "Frankfurt Galaxy", "Stuttgart Surge", "Frankfurt", 24, 31),
+		mockGame("mock-w2-3", 2, day(-12), "13:00", "Berlin Thunder", "Hamburg Sea Devils", "Berlin", 10, 30),
+		mockGame("mock-w2-4", 2, day(-12), "15:00", "Raiders Tirol", "Madrid Bravos", "Innsbruck", 34, 13),
+		mockGame("mock-w2-5", 2, day(-12), "15:00", "Fehervar Enthroners", "Vienna Vikings", "Szekesfehervar", 7, 42),
+		mockGame("mock-w2-6", 2, day(-12), "16:00", "Cologne Centurions", "Paris Musketeers", "Cologne", 21, 19),
+		mockGame("mock-w2-7", 2, day(-12), "17:00", "Nordic Storm", "Rhein Fire", "Copenhagen", 16, 33),
+		mockGame("mock-w2-8", 2, day(-12), "18:00", "Helvetic Mercenaries", "Munich Ravens", "Zurich", 17, 24),
+		// Week 3
+		mockGame("mock-w3-1", 3, day(-6), "15:00", "Vienna Vikings", "Stuttgart Surge", "Vienna", 28, 24),
+		mockGame("mock-w3-2", 3, day(-6), "18:00", "Rhein Fire", "Munich Ravens", "Duisburg", 27, 20),
+		mockGame("mock-w3-3", 3, day(-5), "13:00", "Prague Lions", "Fehervar Enthroners", "Prague", 23, 16),
+		mockGame("mock-w3-4", 3, day(-5), "15:00", "Frankfurt Galaxy", "Cologne Centurions", "Frankfurt", 35, 28),
+		mockGame("mock-w3-5", 3, day(-5), "15:00", "Hamburg Sea Devils", "Raiders Tirol", "Hamburg", 17, 21),
+		mockGame("mock-w3-6", 3, day(-5), "16:00", "Paris Musketeers", "Wroclaw Panthers", "Paris", 13, 10),
+		mockGame("mock-w3-7", 3, day(-5), "17:00", "Berlin Thunder", "Nordic Storm", "Berlin", 24, 22),
+		mockGame("mock-w3-8", 3, day(-5), "18:00", "Madrid Bravos", "Helvetic Mercenaries", "Madrid", 30, 27),
+		// Week 4 (upcoming)
+		mockGame("mock-w4-1", 4, day(1), "15:00", "Wroclaw Panthers", "Vienna Vikings", "Wroclaw", 0, 0),
+		mockGame("mock-w4-2", 4, day(1), "18:00", "Stuttgart Surge", "Paris Musketeers", "Stuttgart", 0, 0),
+		mockGame("mock-w4-3", 4, day(2), "13:00", "Rhein Fire", "Hamburg Sea Devils", "Duisburg", 0, 0),
+		mockGame("mock-w4-4", 4, day(2), "15:00", "Munich Ravens", "Madrid Bravos", "Munich", 0, 0),
+		mockGame("mock-w4-5", 4, day(2), "15:00", "Fehervar Enthroners", "Prague Lions", "Szekesfehervar", 0, 0),
+		mockGame("mock-w4-6", 4, day(2), "16:00", "Cologne Centurions", "Frankfurt Galaxy", "Cologne", 0, 0),
+		mockGame("mock-w4-7", 4, day(2), "17:00", "Nordic Storm", "Berlin Thunder", "Copenhagen", 0, 0),
+		mockGame("mock-w4-8", 4, day(2), "18:00", "Raiders Tirol", "Helvetic Mercenaries", "Innsbruck", 0, 0),
 	}
 }
 
 func insertMockData() {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
 	// Insert mock schedule data
-	scheduleStmt, err := db.Prepare("REPLACE INTO schedule (statcrew_id, home_team, away_team, date, time, game_week, location, home_score, away_score, slug, game_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	scheduleStmt, err := db.PrepareContext(ctx, "REPLACE INTO schedule (statcrew_id, home_team, away_team, date, time, game_week, location, home_score, away_score, slug, game_date, league, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)")
 	if err != nil {
-		log.Printf("Error preparing mock schedule statement: %v", err)
+		logErrorf("Error preparing mock schedule statement: %v", err)
 		return
 	}
 	defer scheduleStmt.Close()
+	defer invalidateStandings()
 
-	mockSchedules := []Schedule{
-		{StatcrewID: "mock1", HomeTeam: "Manchester United", AwayTeam: "Liverpool", Date: "2024-01-15", Time: "20:00", GameWeek: 1, Location: "Manchester", HomeScore: 0, AwayScore: 0, Slug: "mock1", GameDate: "2024-01-15T20:00:00"},
-		{StatcrewID: "mock2", HomeTeam: "Barcelona", AwayTeam: "Real Madrid", Date: "2024-01-16", Time: "21:00", GameWeek: 1, Location: "Barcelona", HomeScore: 0, AwayScore: 0, Slug: "mock2", GameDate: "2024-01-16T21:00:00"},
-		{StatcrewID: "mock3", HomeTeam: "Bayern Munich", AwayTeam: "Borussia Dortmund", Date: "2024-01-17", Time: "19:30", GameWeek: 2, Location: "Munich", HomeScore: 0, AwayScore: 0, Slug: "mock3", GameDate: "2024-01-17T19:30:00"},
-		{StatcrewID: "mock4", HomeTeam: "PSG", AwayTeam: "Marseille", Date: "2024-01-18", Time: "20:45", GameWeek: 2, Location: "Paris", HomeScore: 0, AwayScore: 0, Slug: "mock4", GameDate: "2024-01-18T20:45:00"},
+	if err := clearFetchValidators(ctx); err != nil {
+		logErrorf("Error clearing fetch validators: %v", err)
 	}
 
-	for _, schedule := range mockSchedules {
-		_, err = scheduleStmt.Exec(schedule.StatcrewID, schedule.HomeTeam, schedule.AwayTeam, schedule.Date, schedule.Time, schedule.GameWeek, schedule.Location, schedule.HomeScore, schedule.AwayScore, schedule.Slug, schedule.GameDate)
+	games := mockSchedules(time.Now())
+	for _, schedule := range games {
+		_, err = scheduleStmt.ExecContext(ctx, schedule.StatcrewID, schedule.HomeTeam, schedule.AwayTeam, schedule.Date, schedule.Time, schedule.GameWeek, schedule.Location, schedule.HomeScore, schedule.AwayScore, schedule.Slug, schedule.GameDate, primaryLeague())
 		if err != nil {
-			log.Printf("Error inserting mock schedule: %v", err)
+			logErrorf("Error inserting mock schedule: %v", err)
 		}
 	}
 
-	// Insert mock scoreboard data
-	scoreboardStmt, err := db.Prepare("REPLACE INTO scoreboard (statcrew_id, home_score, away_score, home_record, away_record) VALUES (?, ?, ?, ?, ?)")
+	// Insert mock scoreboard data for the played games
+	scoreboardStmt, err := db.PrepareContext(ctx, "REPLACE INTO scoreboard (statcrew_id, home_score, away_score, home_record, away_record) VALUES (?, ?, ?, ?, ?)")
 	if err != nil {
-		log.Printf("Error preparing mock scoreboard statement: %v", err)
+		logErrorf("Error preparing mock scoreboard statement: %v", err)
 		return
 	}
 	defer scoreboardStmt.Close()
 
-	mockScoreboards := []Scoreboard{
-		{StatcrewID: "mock1", HomeScore: "2", AwayScore: "1", HomeRecord: "5-2", AwayRecord: "3-4"},
-		{StatcrewID: "mock2", HomeScore: "0", AwayScore: "0", HomeRecord: "4-3", AwayRecord: "6-1"},
-		{StatcrewID: "mock3", HomeScore: "3", AwayScore: "2", HomeRecord: "7-0", AwayRecord: "2-5"},
-		{StatcrewID: "mock4", HomeScore: "1", AwayScore: "1", HomeRecord: "3-4", AwayRecord: "4-3"},
+	for _, schedule := range games {
+		if !isPlayed(schedule.HomeScore, schedule.AwayScore) {
+			continue
+		}
+		_, err = scoreboardStmt.ExecContext(ctx, schedule.StatcrewID, schedule.HomeScore, schedule.AwayScore, "", "")
+		if err != nil {
+			logErrorf("Error inserting mock scoreboard: %v", err)
+		}
+	}
+
+	logInfof("Mock data inserted successfully")
+}
+
+// dataTables are the tables holding fetched game data, removed together so
+// no box score or score history outlives its game
+var dataTables = []string{"schedule", "scoreboard", "boxscore", "score_history"}
+
+// clearData removes all rows of the data tables in one transaction and
+// returns the number of removed rows per table
+func clearData(ctx context.Context) (map[string]int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	for _, scoreboard := range mockScoreboards {
-		_, err = scoreboardStmt.Exec(scoreboard.StatcrewID, scoreboard.HomeScore, scoreboard.AwayScore, scoreboard.HomeRecord, scoreboard.AwayRecord)
+	removed := make(map[string]int64)
+	for _, table := range dataTables {
+		result, err := tx.ExecContext(ctx, "DELETE FROM "+table)
 		if err != nil {
-			log.Printf("Error inserting mock scoreboard: %v", err)
+			return nil, fmt.Errorf("clearing %s: %w", table, err)
 		}
+		removed[table], _ = result.RowsAffected()
 	}
-
-	log.Println("Mock data inserted successfully")
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	invalidateStandings()
+	return removed, nil
 }
 
-func insertMockDataHandler(c *gin.Context) {
-	// Clear existing data first
-	db.Exec("DELETE FROM schedule")
-	db.Exec("DELETE FROM scoreboard")
+// replaceWithMockData clears the stored data and inserts the mock data. If
+// clearing fails nothing is removed and no mock data is inserted.
+func replaceWithMockData(ctx context.Context) error {
+	if _, err := clearData(ctx); err != nil {
+		return err
+	}
 
 	insertMockData()
+	return nil
+}
+
+func insertMockDataHandler(c *gin.Context) {
+	if err := replaceWithMockData(c.Request.Context()); err != nil {
+		respondDBError(c, err)
+		return
+	}
 
-	if c.GetHeader("HX-Request") == "true" {
+	if wantsHTML(c) {
 		c.HTML(http.StatusOK, "refresh.html", gin.H{"message": "Mock data inserted successfully"})
 	} else {
 		c.JSON(http.StatusOK, gin.H{"message": "Mock data inserted successfully"})
 	}
 }
+
+// clearDataHandler removes all schedule and scoreboard rows without
+// inserting anything new
+func clearDataHandler(c *gin.Context) {
+	removed, err := clearData(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+	if err := clearFetchValidators(c.Request.Context()); err != nil {
+		logErrorf("Error clearing fetch validators: %v", err)
+	}
+
+	logInfof("Cleared database: removed %d schedule, %d scoreboard, %d box score and %d score history rows", removed["schedule"], removed["scoreboard"], removed["boxscore"], removed["score_history"])
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Data cleared successfully",
+		"removed": removed["schedule"] + removed["scoreboard"] + removed["boxscore"] + removed["score_history"],
+		"tables":  removed,
+	})
+}
@@ -0,0 +1,839 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	os.Exit(m.Run())
+}
+
+// testGames is a small deterministic season of the NORTH division: two played
+// weeks and one week far in the future
+var testGames = []Schedule{
+	// Week 1
+	mockGame("test-w1-1", 1, "2024-05-18", "15:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 28, 14),
+	mockGame("test-w1-2", 1, "2024-05-18", "18:00", "Hamburg Sea Devils", "Nordic Storm", "Hamburg", 21, 24),
+	// Week 2
+	mockGame("test-w2-1", 2, "2024-05-25", "15:00", "Berlin Thunder", "Hamburg Sea Devils", "Berlin", 17, 10),
+	mockGame("test-w2-2", 2, "2024-05-25", "18:00", "Nordic Storm", "Rhein Fire", "Copenhagen", 13, 35),
+	// Week 3 (upcoming)
+	mockGame("test-w3-1", 3, "2099-06-01", "15:00", "Rhein Fire", "Hamburg Sea Devils", "Duisburg", 0, 0),
+	mockGame("test-w3-2", 3, "2099-06-01", "18:00", "Berlin Thunder", "Nordic Storm", "Berlin", 0, 0),
+}
+
+// loadTestConfig resets the configuration to the defaults, tests change
+// single fields afterwards
+func loadTestConfig(t *testing.T) {
+	t.Helper()
+	if err := loadConfig(); err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	logLevel = levelError
+}
+
+// newTestRouter loads the default configuration, opens an empty in-memory
+// database and returns the application's router. The database is closed
+// when the test ends.
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	loadTestConfig(t)
+
+	if err := openDB("file::memory:?cache=shared"); err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		invalidateStandings()
+	})
+	invalidateStandings()
+
+	r, err := setupRouter()
+	if err != nil {
+		t.Fatalf("setupRouter: %v", err)
+	}
+	return r
+}
+
+// seedGames stores the games like a fetch would
+func seedGames(t *testing.T, games []Schedule) {
+	t.Helper()
+	seeded := append([]Schedule(nil), games...)
+	if _, err := storeSchedules(context.Background(), seeded, false); err != nil {
+		t.Fatalf("storeSchedules: %v", err)
+	}
+}
+
+// serve sends a request to the router and returns the recorded response
+func serve(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+// decodeResponse decodes a JSON response body into v
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body.String(), err)
+	}
+}
+
+// scheduleBody encodes games like the upstream schedule API
+func scheduleBody(t *testing.T, games []Schedule) []byte {
+	t.Helper()
+	body, err := json.Marshal(games)
+	if err != nil {
+		t.Fatalf("encoding games: %v", err)
+	}
+	return body
+}
+
+// useScheduleSources points the primary league at the given schedule URLs
+func useScheduleSources(urls ...string) {
+	config.Leagues[0].URLs = urls
+}
+
+func TestGetSchedule(t *testing.T) {
+	r := newTestRouter(t)
+	seedGames(t, testGames)
+
+	var data ScheduleData
+	decodeResponse(t, serve(r, http.MethodGet, "/api/schedule"), &data)
+
+	if len(data.FinishedMatches) != 2 || data.FinishedMatches[0].Week != 2 || data.FinishedMatches[1].Week != 1 {
+		t.Fatalf("finished weeks = %+v, want weeks 2 and 1", data.FinishedMatches)
+	}
+	if len(data.UpcomingMatches) != 1 || data.UpcomingMatches[0].Week != 3 || len(data.UpcomingMatches[0].Matches) != 2 {
+		t.Fatalf("upcoming weeks = %+v, want week 3 with two games", data.UpcomingMatches)
+	}
+
+	game := data.FinishedMatches[1].Matches[0]
+	if game.StatcrewID != "test-w1-1" || game.HomeScore != 28 || game.AwayScore != 14 {
+		t.Errorf("first game = %+v, want test-w1-1 28-14", game)
+	}
+	if game.Status != "final" || game.HomeLogo != "rhf.png" {
+		t.Errorf("first game status %q logo %q, want final and rhf.png", game.Status, game.HomeLogo)
+	}
+}
+
+func TestGetScheduleEmpty(t *testing.T) {
+	r := newTestRouter(t)
+
+	var data ScheduleData
+	decodeResponse(t, serve(r, http.MethodGet, "/api/schedule"), &data)
+	if len(data.FinishedMatches) != 0 || len(data.UpcomingMatches) != 0 {
+		t.Errorf("schedule = %+v, want no games", data)
+	}
+}
+
+func TestGetScoreboard(t *testing.T) {
+	r := newTestRouter(t)
+	seedGames(t, testGames)
+
+	var standings []DivisionData
+	decodeResponse(t, serve(r, http.MethodGet, "/api/scoreboard?division=north"), &standings)
+	if len(standings) != 1 || standings[0].Division != "NORTH" {
+		t.Fatalf("standings = %+v, want only NORTH", standings)
+	}
+
+	want := []struct {
+		team          string
+		record        string
+		pointsFor     int
+		pointsAgainst int
+	}{
+		{"Rhein Fire", "2-0", 63, 27},
+		{"Berlin Thunder", "1-1", 31, 38},
+		{"Nordic Storm", "1-1", 37, 56},
+		{"Hamburg Sea Devils", "0-2", 31, 41},
+	}
+	teams := standings[0].Teams
+	if len(teams) != len(want) {
+		t.Fatalf("got %d teams, want %d", len(teams), len(want))
+	}
+	for i, w := range want {
+		team := teams[i]
+		if team.TeamName != w.team || team.Record != w.record || team.Position != i+1 {
+			t.Errorf("position %d = %s %s (#%d), want %s %s", i+1, team.TeamName, team.Record, team.Position, w.team, w.record)
+		}
+		if team.PointsFor != w.pointsFor || team.PointsAgainst != w.pointsAgainst {
+			t.Errorf("%s points %d-%d, want %d-%d", team.TeamName, team.PointsFor, team.PointsAgainst, w.pointsFor, w.pointsAgainst)
+		}
+	}
+}
+
+func TestGetScoreboardUnknownDivision(t *testing.T) {
+	r := newTestRouter(t)
+
+	w := serve(r, http.MethodGet, "/api/scoreboard?division=central")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestScoresAreNumbers(t *testing.T) {
+	r := newTestRouter(t)
+	config.FetchScoreboard = true
+
+	// The schedule has no result for week 2 yet, the scoreboard has: one row
+	// as stored now and one with the upstream's text of older versions
+	games := append([]Schedule(nil), testGames...)
+	games[2].HomeScore, games[2].AwayScore = 0, 0
+	games[3].HomeScore, games[3].AwayScore = 0, 0
+	seedGames(t, games)
+	for _, row := range [][]interface{}{{"test-w2-1", 17, 10}, {"test-w2-2", "13", "35"}} {
+		if _, err := db.Exec("REPLACE INTO scoreboard (statcrew_id, home_score, away_score) VALUES (?, ?, ?)", row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var schedule struct {
+		FinishedMatches []struct{ Matches []map[string]interface{} }
+		UpcomingMatches []struct{ Matches []map[string]interface{} }
+	}
+	decodeResponse(t, serve(r, http.MethodGet, "/api/schedule"), &schedule)
+	for _, weeks := range [][]struct{ Matches []map[string]interface{} }{schedule.FinishedMatches, schedule.UpcomingMatches} {
+		for _, week := range weeks {
+			for _, match := range week.Matches {
+				for _, key := range []string{"homeScore", "awayScore"} {
+					if _, ok := match[key].(float64); !ok {
+						t.Errorf("%v %s is %T, want a number", match["statcrewID"], key, match[key])
+					}
+				}
+			}
+		}
+	}
+
+	var standings []DivisionData
+	decodeResponse(t, serve(r, http.MethodGet, "/api/scoreboard"), &standings)
+	rheinFire := findStanding(standings, "Rhein Fire", teamDivisions)
+	if rheinFire.Record != "2-0" || rheinFire.PointsFor != 63 {
+		t.Errorf("Rhein Fire %s with %d points, want 2-0 and 63 with the scoreboard scores merged", rheinFire.Record, rheinFire.PointsFor)
+	}
+}
+
+func TestGetScoreboardPreseason(t *testing.T) {
+	tests := []struct {
+		name  string
+		games []Schedule
+	}{
+		{"no games", nil},
+		{"only upcoming games", testGames[4:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(t)
+			seedGames(t, tt.games)
+
+			var standings []DivisionData
+			decodeResponse(t, serve(r, http.MethodGet, "/api/scoreboard"), &standings)
+
+			count := 0
+			for _, division := range standings {
+				for _, team := range division.Teams {
+					count++
+					if team.Record != "0-0" || team.GamesPlayed != 0 || team.Streak != "" {
+						t.Errorf("%s record %s after %d games, streak %q, want 0-0 without games", team.TeamName, team.Record, team.GamesPlayed, team.Streak)
+					}
+					if team.SoS != 0 || team.SoV != 0 {
+						t.Errorf("%s SoS %v SoV %v, want 0", team.TeamName, team.SoS, team.SoV)
+					}
+				}
+			}
+			if want := len(knownTeams(teamDivisions)); count != want {
+				t.Errorf("got %d teams, want all %d teams of the division map", count, want)
+			}
+		})
+	}
+}
+
+func TestComputeStandingsPoints(t *testing.T) {
+	loadTestConfig(t)
+	standings := computeStandings(testGames, teamDivisions)
+
+	for _, want := range []TeamStanding{
+		{TeamName: "Rhein Fire", PointsFor: 63, PointsAgainst: 27, PointDiff: 36},
+		{TeamName: "Berlin Thunder", PointsFor: 31, PointsAgainst: 38, PointDiff: -7},
+		{TeamName: "Nordic Storm", PointsFor: 37, PointsAgainst: 56, PointDiff: -19},
+		{TeamName: "Hamburg Sea Devils", PointsFor: 31, PointsAgainst: 41, PointDiff: -10},
+	} {
+		got := findStanding(standings, want.TeamName, teamDivisions)
+		if got.PointsFor != want.PointsFor || got.PointsAgainst != want.PointsAgainst || got.PointDiff != want.PointDiff {
+			t.Errorf("%s PF/PA/PD = %d/%d/%d, want %d/%d/%d", want.TeamName,
+				got.PointsFor, got.PointsAgainst, got.PointDiff, want.PointsFor, want.PointsAgainst, want.PointDiff)
+		}
+	}
+}
+
+func TestRankBeforePointDiff(t *testing.T) {
+	a := TeamStanding{TeamName: "B", Wins: 2, Losses: 1, SoV: 0.5, SoS: 0.5, PointDiff: 10}
+	b := TeamStanding{TeamName: "A", Wins: 2, Losses: 1, SoV: 0.5, SoS: 0.5, PointDiff: 3}
+	if !rankBefore(a, b) || rankBefore(b, a) {
+		t.Error("the better point differential should rank first when SoV and SoS are tied")
+	}
+
+	b.SoS = 0.6
+	if rankBefore(a, b) {
+		t.Error("SoS should decide before the point differential")
+	}
+}
+
+func TestMarkClinchStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		teams          []TeamStanding
+		wantClinched   []bool
+		wantEliminated []bool
+	}{
+		{
+			name:           "lead larger than the games left",
+			teams:          []TeamStanding{{Wins: 10, GamesRemaining: 2}, {Wins: 7, GamesRemaining: 2}},
+			wantClinched:   []bool{true, false},
+			wantEliminated: []bool{false, true},
+		},
+		{
+			name:           "runner-up can still tie",
+			teams:          []TeamStanding{{Wins: 10, GamesRemaining: 2}, {Wins: 8, GamesRemaining: 2}},
+			wantClinched:   []bool{false, false},
+			wantEliminated: []bool{false, false},
+		},
+		{
+			name:           "start of the season",
+			teams:          []TeamStanding{{GamesRemaining: 12}, {GamesRemaining: 12}, {GamesRemaining: 12}},
+			wantClinched:   []bool{false, false, false},
+			wantEliminated: []bool{false, false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markClinchStatus(tt.teams)
+			for i, team := range tt.teams {
+				if team.Clinched != tt.wantClinched[i] || team.Eliminated != tt.wantEliminated[i] {
+					t.Errorf("team %d clinched %v eliminated %v, want %v %v", i, team.Clinched, team.Eliminated, tt.wantClinched[i], tt.wantEliminated[i])
+				}
+			}
+		})
+	}
+}
+
+func TestComputeStandingsClinched(t *testing.T) {
+	loadTestConfig(t)
+	config.GamesPerTeam = 2
+
+	standings := computeStandings(testGames, teamDivisions)
+	if leader := findStanding(standings, "Rhein Fire", teamDivisions); !leader.Clinched || leader.Eliminated {
+		t.Errorf("Rhein Fire clinched %v eliminated %v, want clinched", leader.Clinched, leader.Eliminated)
+	}
+	for _, team := range []string{"Berlin Thunder", "Nordic Storm", "Hamburg Sea Devils"} {
+		if standing := findStanding(standings, team, teamDivisions); standing.Clinched || !standing.Eliminated {
+			t.Errorf("%s clinched %v eliminated %v, want eliminated", team, standing.Clinched, standing.Eliminated)
+		}
+	}
+}
+
+func TestNewGameDetail(t *testing.T) {
+	tests := []struct {
+		name       string
+		home, away int
+		wantWinner string
+		wantMargin int // -1 for no margin
+	}{
+		{"home win", 28, 14, "home", 14},
+		{"away win", 13, 35, "away", 22},
+		{"tie", 17, 17, "tie", 0},
+		{"unplayed", 0, 0, "unplayed", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := mockGame("test", 1, "2024-05-18", "15:00", "Rhein Fire", "Berlin Thunder", "Duisburg", tt.home, tt.away)
+			detail := newGameDetail(game, time.Now())
+			if detail.Winner != tt.wantWinner {
+				t.Errorf("winner %q, want %q", detail.Winner, tt.wantWinner)
+			}
+			switch {
+			case tt.wantMargin < 0 && detail.Margin != nil:
+				t.Errorf("margin %d, want none", *detail.Margin)
+			case tt.wantMargin >= 0 && (detail.Margin == nil || *detail.Margin != tt.wantMargin):
+				t.Errorf("margin %v, want %d", detail.Margin, tt.wantMargin)
+			}
+		})
+	}
+}
+
+func TestStandingsCachedUntilInvalidated(t *testing.T) {
+	newTestRouter(t)
+	seedGames(t, testGames)
+
+	standings, err := getStandings(context.Background())
+	if err != nil {
+		t.Fatalf("getStandings: %v", err)
+	}
+	if got := findStanding(standings, "Rhein Fire", teamDivisions).Record; got != "2-0" {
+		t.Fatalf("Rhein Fire record %s, want 2-0", got)
+	}
+
+	// A result for week 3 is only picked up once the cache is dropped
+	if _, err := db.Exec("UPDATE schedule SET home_score = 10, away_score = 20 WHERE statcrew_id = ?", "test-w3-1"); err != nil {
+		t.Fatalf("updating the schedule: %v", err)
+	}
+	standings, err = getStandings(context.Background())
+	if err != nil {
+		t.Fatalf("getStandings: %v", err)
+	}
+	if got := findStanding(standings, "Rhein Fire", teamDivisions).Record; got != "2-0" {
+		t.Errorf("Rhein Fire record before invalidating %s, want the cached 2-0", got)
+	}
+
+	invalidateStandings()
+	standings, err = getStandings(context.Background())
+	if err != nil {
+		t.Fatalf("getStandings: %v", err)
+	}
+	if got := findStanding(standings, "Rhein Fire", teamDivisions).Record; got != "2-1" {
+		t.Errorf("Rhein Fire record after invalidating %s, want 2-1", got)
+	}
+	if got := findStanding(standings, "Hamburg Sea Devils", teamDivisions).Record; got != "1-2" {
+		t.Errorf("Hamburg Sea Devils record after invalidating %s, want 1-2", got)
+	}
+}
+
+func TestFilterValidSchedules(t *testing.T) {
+	loadTestConfig(t)
+
+	valid := testGames[0]
+	missingID := testGames[1]
+	missingID.StatcrewID = " "
+	missingHome := testGames[2]
+	missingHome.HomeTeam = ""
+	missingAway := testGames[3]
+	missingAway.AwayTeam = ""
+
+	kept, skipped := filterValidSchedules([]Schedule{missingID, valid, missingHome, missingAway, testGames[4]})
+	if skipped != 3 {
+		t.Errorf("skipped %d entries, want 3", skipped)
+	}
+	if len(kept) != 2 || kept[0].StatcrewID != valid.StatcrewID || kept[1].StatcrewID != testGames[4].StatcrewID {
+		t.Errorf("kept %+v, want %s and %s", kept, valid.StatcrewID, testGames[4].StatcrewID)
+	}
+}
+
+func TestGetScheduleSort(t *testing.T) {
+	r := newTestRouter(t)
+	games := append([]Schedule(nil), testGames...)
+	games = append(games, mockGame("test-w4-1", 4, "2099-06-08", "15:00", "Nordic Storm", "Rhein Fire", "Copenhagen", 0, 0))
+	seedGames(t, games)
+
+	weekNumbers := func(weeks []GameWeek) []int {
+		numbers := []int{}
+		for _, week := range weeks {
+			numbers = append(numbers, week.Week)
+		}
+		return numbers
+	}
+
+	tests := []struct {
+		query         string
+		wantFinished  []int
+		wantUpcoming  []int
+		wantFirstGame string // First game of the first finished week
+	}{
+		{"", []int{2, 1}, []int{3, 4}, "test-w2-1"},
+		{"?sort=date", []int{1, 2}, []int{3, 4}, "test-w1-1"},
+		{"?sort=-date", []int{2, 1}, []int{4, 3}, "test-w2-2"},
+		{"?sort=gameweek", []int{1, 2}, []int{3, 4}, "test-w1-1"},
+		{"?sort=-gameweek", []int{2, 1}, []int{4, 3}, "test-w2-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var data ScheduleData
+			decodeResponse(t, serve(r, http.MethodGet, "/api/schedule"+tt.query), &data)
+			finished, upcoming := weekNumbers(data.FinishedMatches), weekNumbers(data.UpcomingMatches)
+			if fmt.Sprint(finished) != fmt.Sprint(tt.wantFinished) || fmt.Sprint(upcoming) != fmt.Sprint(tt.wantUpcoming) {
+				t.Fatalf("weeks finished %v upcoming %v, want %v and %v", finished, upcoming, tt.wantFinished, tt.wantUpcoming)
+			}
+			if got := data.FinishedMatches[0].Matches[0].StatcrewID; got != tt.wantFirstGame {
+				t.Errorf("first game %s, want %s", got, tt.wantFirstGame)
+			}
+		})
+	}
+
+	if w := serve(r, http.MethodGet, "/api/schedule?sort=home_team"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown sort status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	// GOELF_DEFAULT_SORT orders the weeks too, ?sort= still wins
+	config.DefaultSort = "-gameweek"
+	t.Cleanup(func() { config.DefaultSort = "" })
+	for query, want := range map[string][2][]int{
+		"":           {{2, 1}, {4, 3}},
+		"?sort=date": {{1, 2}, {3, 4}},
+	} {
+		var data ScheduleData
+		decodeResponse(t, serve(r, http.MethodGet, "/api/schedule"+query), &data)
+		finished, upcoming := weekNumbers(data.FinishedMatches), weekNumbers(data.UpcomingMatches)
+		if fmt.Sprint(finished) != fmt.Sprint(want[0]) || fmt.Sprint(upcoming) != fmt.Sprint(want[1]) {
+			t.Errorf("%q with GOELF_DEFAULT_SORT=-gameweek: weeks finished %v upcoming %v, want %v and %v", query, finished, upcoming, want[0], want[1])
+		}
+	}
+}
+
+func TestFetchScheduleNotModified(t *testing.T) {
+	newTestRouter(t)
+
+	body := scheduleBody(t, testGames)
+	var ifNoneMatch []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(body)
+	}))
+	defer upstream.Close()
+	useScheduleSources(upstream.URL)
+
+	fetchSchedule()
+	if got := countGames(t); got != len(testGames) {
+		t.Fatalf("first fetch stored %d games, want %d", got, len(testGames))
+	}
+
+	// The upstream answers 304, the stored games are left alone
+	if _, err := db.Exec("DELETE FROM schedule WHERE statcrew_id = ?", "test-w3-2"); err != nil {
+		t.Fatal(err)
+	}
+	fetchSchedule()
+	if got := countGames(t); got != len(testGames)-1 {
+		t.Errorf("not modified fetch left %d games, want %d", got, len(testGames)-1)
+	}
+
+	want := []string{"", `"v1"`}
+	if fmt.Sprint(ifNoneMatch) != fmt.Sprint(want) {
+		t.Errorf("If-None-Match headers %q, want %q", ifNoneMatch, want)
+	}
+}
+
+// countGames returns the number of stored games
+func countGames(t *testing.T) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM schedule").Scan(&count); err != nil {
+		t.Fatalf("counting games: %v", err)
+	}
+	return count
+}
+
+func TestGamesRemaining(t *testing.T) {
+	loadTestConfig(t)
+	config.GamesPerTeam = 12
+
+	for _, tt := range []struct{ played, want int }{
+		{0, 12},
+		{5, 7},
+		{12, 0},
+		{13, 0}, // Playoff or rescheduled games never make it negative
+	} {
+		if got := gamesRemaining(tt.played); got != tt.want {
+			t.Errorf("gamesRemaining(%d) = %d, want %d", tt.played, got, tt.want)
+		}
+	}
+
+	standing := findStanding(computeStandings(testGames, teamDivisions), "Rhein Fire", teamDivisions)
+	if standing.GamesPlayed != 2 || standing.GamesRemaining != 10 {
+		t.Errorf("Rhein Fire played %d remaining %d, want 2 and 10", standing.GamesPlayed, standing.GamesRemaining)
+	}
+}
+
+// resultGames builds a season of Rhein Fire games with the given results
+// ('W', 'L' or 'T'), passed in an order that differs from the dates
+func resultGames(results string) []Schedule {
+	games := make([]Schedule, len(results))
+	for i, result := range results {
+		home, away := 20, 20
+		switch result {
+		case 'W':
+			away = 10
+		case 'L':
+			home = 10
+		}
+		date := time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 7*i).Format("2006-01-02")
+		games[i] = mockGame(fmt.Sprintf("g%d", i+1), i+1, date, "15:00", "Rhein Fire", "Berlin Thunder", "Duisburg", home, away)
+	}
+	// Reverse, so only sorting by date gets the order right
+	for i, j := 0, len(games)-1; i < j; i, j = i+1, j-1 {
+		games[i], games[j] = games[j], games[i]
+	}
+	return games
+}
+
+func TestComputeStandingsForm(t *testing.T) {
+	loadTestConfig(t)
+	config.FormGames = 5
+
+	tests := []struct {
+		results string
+		want    string
+	}{
+		{"", ""},
+		{"WL", "WL"},
+		{"WWLWT", "WWLWT"},
+		{"LLWWLWT", "WWLWT"}, // Only the last five
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.results, func(t *testing.T) {
+			standing := findStanding(computeStandings(resultGames(tt.results), teamDivisions), "Rhein Fire", teamDivisions)
+			if standing.Form != tt.want {
+				t.Errorf("form %q, want %q", standing.Form, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeStandingsStreak(t *testing.T) {
+	loadTestConfig(t)
+
+	tests := []struct {
+		name    string
+		results string
+		want    string
+	}{
+		{"no games", "", ""},
+		{"win streak", "LWWW", "W3"},
+		{"loss streak", "WWLL", "L2"},
+		{"broken by a tie", "WWWT", "T1"},
+		{"win after a tie", "WWTW", "W1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standing := findStanding(computeStandings(resultGames(tt.results), teamDivisions), "Rhein Fire", teamDivisions)
+			if standing.Streak != tt.want {
+				t.Errorf("streak %q, want %q", standing.Streak, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadResponseBodyLimit(t *testing.T) {
+	loadTestConfig(t)
+	config.MaxResponseBytes = 16
+
+	data, err := readResponseBody(strings.NewReader(strings.Repeat("x", 16)))
+	if err != nil || len(data) != 16 {
+		t.Errorf("body at the limit: %d bytes, %v", len(data), err)
+	}
+
+	_, err = readResponseBody(strings.NewReader(strings.Repeat("x", int(config.MaxResponseBytes)+1)))
+	if err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Errorf("oversized body: %v, want a size error", err)
+	}
+}
+
+func TestFetchScheduleTooLarge(t *testing.T) {
+	newTestRouter(t)
+	body := scheduleBody(t, testGames)
+	config.MaxResponseBytes = int64(len(body)) - 1
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer upstream.Close()
+	useScheduleSources(upstream.URL)
+
+	if _, err := updateSchedule(config.Leagues[0], false); err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Fatalf("updateSchedule: %v, want a size error", err)
+	}
+	if stored := countGames(t); stored != 0 {
+		t.Errorf("stored %d games, want none", stored)
+	}
+}
+
+func TestFetchScheduleSkipsWhileRunning(t *testing.T) {
+	newTestRouter(t)
+
+	body := scheduleBody(t, testGames)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		w.Write(body)
+	}))
+	defer upstream.Close()
+	useScheduleSources(upstream.URL)
+
+	first := make(chan struct{})
+	go func() {
+		fetchSchedule()
+		close(first)
+	}()
+	<-started
+
+	// Fetches triggered while the first one waits for the upstream return
+	// right away without a request
+	const concurrent = 5
+	var wg sync.WaitGroup
+	for i := 0; i < concurrent; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fetchSchedule()
+		}()
+	}
+	wg.Wait()
+
+	close(release)
+	<-first
+	if n := requests.Load(); n != 1 {
+		t.Errorf("upstream saw %d requests, want 1", n)
+	}
+
+	// Once the fetch is done the next one runs again
+	fetchSchedule()
+	if n := requests.Load(); n != 2 {
+		t.Errorf("upstream saw %d requests after the first fetch finished, want 2", n)
+	}
+}
+
+func TestGetScoreboardAsOf(t *testing.T) {
+	r := newTestRouter(t)
+	seedGames(t, testGames)
+
+	tests := []struct {
+		query       string
+		wantRecords map[string]string
+	}{
+		{"", map[string]string{"Rhein Fire": "2-0", "Berlin Thunder": "1-1", "Hamburg Sea Devils": "0-2"}},
+		{"?asOf=2024-05-20", map[string]string{"Rhein Fire": "1-0", "Berlin Thunder": "0-1", "Hamburg Sea Devils": "0-1"}},
+		{"?throughWeek=1", map[string]string{"Rhein Fire": "1-0", "Berlin Thunder": "0-1", "Hamburg Sea Devils": "0-1"}},
+		{"?asOf=2024-05-25", map[string]string{"Rhein Fire": "2-0", "Berlin Thunder": "1-1", "Hamburg Sea Devils": "0-2"}},
+		{"?asOf=2024-01-01", map[string]string{"Rhein Fire": "0-0", "Berlin Thunder": "0-0", "Hamburg Sea Devils": "0-0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var standings []DivisionData
+			decodeResponse(t, serve(r, http.MethodGet, "/api/scoreboard"+tt.query), &standings)
+			for team, want := range tt.wantRecords {
+				if got := findStanding(standings, team, teamDivisions).Record; got != want {
+					t.Errorf("%s record %s, want %s", team, got, want)
+				}
+			}
+		})
+	}
+
+	for _, query := range []string{"?asOf=20.05.2024", "?throughWeek=0", "?asOf=2024-05-20&throughWeek=1"} {
+		if w := serve(r, http.MethodGet, "/api/scoreboard"+query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestFetchScheduleFallsBackToMirror(t *testing.T) {
+	newTestRouter(t)
+	defer func(delay time.Duration) { scheduleRetryDelay = delay }(scheduleRetryDelay)
+	scheduleRetryDelay = time.Millisecond
+
+	var primaryRequests atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryRequests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	body := scheduleBody(t, testGames)
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer mirror.Close()
+	useScheduleSources(primary.URL, mirror.URL)
+
+	if _, err := updateSchedule(config.Leagues[0], false); err != nil {
+		t.Fatalf("updateSchedule: %v", err)
+	}
+	if n := primaryRequests.Load(); n != scheduleSourceAttempts {
+		t.Errorf("primary saw %d requests, want %d", n, scheduleSourceAttempts)
+	}
+	if got := countGames(t); got != len(testGames) {
+		t.Errorf("stored %d games, want %d", got, len(testGames))
+	}
+
+	fetchHealth.Lock()
+	lastSource := fetchHealth.lastSource
+	fetchHealth.Unlock()
+	if lastSource != mirror.URL {
+		t.Errorf("recorded source %q, want the mirror %q", lastSource, mirror.URL)
+	}
+	if stored, err := getMetadata(context.Background(), metaScheduleSource); err != nil || stored != mirror.URL {
+		t.Errorf("stored source %q (%v), want the mirror %q", stored, err, mirror.URL)
+	}
+}
+
+func TestReplaceWithMockData(t *testing.T) {
+	r := newTestRouter(t)
+	seedGames(t, testGames)
+	if _, err := db.Exec("INSERT INTO boxscore (statcrew_id, data) VALUES (?, '{}')", testGames[0].StatcrewID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO score_history (statcrew_id, new_home_score, new_away_score) VALUES (?, 28, 14)", testGames[0].StatcrewID); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := serve(r, http.MethodGet, "/api/mock"); w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", w.Code, w.Body)
+	}
+	if got, want := countGames(t), len(mockSchedules(time.Now())); got != want {
+		t.Errorf("stored %d games, want the %d mock games", got, want)
+	}
+	for _, table := range []string{"boxscore", "score_history"} {
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%d %s rows left over from the replaced games", count, table)
+		}
+	}
+}
+
+func TestReplaceWithMockDataFailureKeepsData(t *testing.T) {
+	newTestRouter(t)
+	seedGames(t, testGames)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := replaceWithMockData(ctx); err == nil {
+		t.Fatal("replaceWithMockData succeeded with a cancelled context")
+	}
+	if got := countGames(t); got != len(testGames) {
+		t.Errorf("stored %d games after the failed replace, want the %d original games", got, len(testGames))
+	}
+}
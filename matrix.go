@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// matrixNotPlayed marks pairs of teams that haven't played each other
+const matrixNotPlayed = "—"
+
+// ResultMatrix holds the head-to-head results among all teams
+type ResultMatrix struct {
+	Teams []string // Row and column order, sorted by name
+	// Results[team][opponent] are the team's results against the opponent in
+	// chronological order, e.g. "WL", or matrixNotPlayed. A team has no
+	// entry for itself.
+	Results map[string]map[string]string
+}
+
+// matrixTeams returns the teams of the games and the league's division map,
+// sorted by name. Spellings that normalize to the same name are only listed
+// once, preferring the one used in the games.
+func matrixTeams(games []Schedule, divisions map[string]string) []string {
+	seen := make(map[string]bool)
+	var teams []string
+	add := func(team string) {
+		if normalized := normalizeTeamName(team); !seen[normalized] {
+			seen[normalized] = true
+			teams = append(teams, team)
+		}
+	}
+	for _, game := range games {
+		add(game.HomeTeam)
+		add(game.AwayTeam)
+	}
+	for _, team := range knownTeams(divisions) {
+		add(team)
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// computeResultMatrix builds the head-to-head grid from the played games
+func computeResultMatrix(games []Schedule, divisions map[string]string) ResultMatrix {
+	matrix := ResultMatrix{
+		Teams:   matrixTeams(games, divisions),
+		Results: make(map[string]map[string]string),
+	}
+	for _, team := range matrix.Teams {
+		row := make(map[string]string, len(matrix.Teams)-1)
+		for _, opponent := range matrix.Teams {
+			if opponent != team {
+				row[opponent] = ""
+			}
+		}
+		matrix.Results[team] = row
+	}
+
+	// Games may spell a team differently than the name chosen for it
+	names := make(map[string]string, len(matrix.Teams))
+	for _, team := range matrix.Teams {
+		names[normalizeTeamName(team)] = team
+	}
+
+	for _, game := range playedGames(games) {
+		home, away := names[normalizeTeamName(game.HomeTeam)], names[normalizeTeamName(game.AwayTeam)]
+		if home == away {
+			continue
+		}
+		homeResult, awayResult := "T", "T"
+		if game.HomeScore > game.AwayScore {
+			homeResult, awayResult = "W", "L"
+		} else if game.AwayScore > game.HomeScore {
+			homeResult, awayResult = "L", "W"
+		}
+		matrix.Results[home][away] += homeResult
+		matrix.Results[away][home] += awayResult
+	}
+
+	for _, row := range matrix.Results {
+		for opponent, results := range row {
+			if results == "" {
+				row[opponent] = matrixNotPlayed
+			}
+		}
+	}
+	return matrix
+}
+
+// getMatrix returns the head-to-head results among all teams
+func getMatrix(c *gin.Context) {
+	games, err := loadStandingsGames(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, computeResultMatrix(games, divisionMap(leagueFromContext(c.Request.Context()))))
+}
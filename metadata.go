@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Metadata keys
+const (
+	metaScheduleETag         = "schedule_etag"
+	metaScheduleLastModified = "schedule_last_modified"
+	metaScheduleSource       = "schedule_source" // URL the validators belong to
+	metaDBCheck              = "db_check"        // Time of the last database self-check
+)
+
+// getMetadata returns a stored metadata value, or "" if it doesn't exist
+func getMetadata(ctx context.Context, key string) (string, error) {
+	var value string
+	err := db.QueryRowContext(ctx, "SELECT value FROM metadata WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+// setMetadata stores a metadata value
+func setMetadata(ctx context.Context, key, value string) error {
+	_, err := db.ExecContext(ctx, "REPLACE INTO metadata (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)", key, value)
+	return err
+}
+
+// clearFetchValidators forgets the stored ETag and Last-Modified of every
+// league, so the next fetch downloads the full schedule. It must be called
+// whenever the schedule is replaced by something other than a fetch.
+func clearFetchValidators(ctx context.Context) error {
+	for _, league := range config.Leagues {
+		_, err := db.ExecContext(ctx, "DELETE FROM metadata WHERE key IN (?, ?)",
+			leagueMetaKey(metaScheduleETag, league.ID), leagueMetaKey(metaScheduleLastModified, league.ID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
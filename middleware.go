@@ -0,0 +1,93 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireAdmin protects an endpoint with the GOELF_ADMIN_TOKEN. The token is
+// passed as "Authorization: Bearer <token>". Without a configured token all
+// admin endpoints are disabled.
+func requireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if config.AdminToken == "" {
+			respondError(c, http.StatusForbidden, codeForbidden, "admin endpoints are disabled")
+			return
+		}
+
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(config.AdminToken)) != 1 {
+			respondError(c, http.StatusUnauthorized, codeUnauthorized, "invalid admin token")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// timingWriter adds the Server-Timing header just before the response
+// headers are written
+type timingWriter struct {
+	gin.ResponseWriter
+	start time.Time
+	set   bool
+}
+
+func (w *timingWriter) setTiming() {
+	if w.set || w.Written() {
+		return
+	}
+	w.set = true
+	elapsed := float64(time.Since(w.start).Microseconds()) / 1000
+	w.Header().Set("Server-Timing", fmt.Sprintf("app;dur=%.1f", elapsed))
+}
+
+func (w *timingWriter) WriteHeader(code int) {
+	w.setTiming()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timingWriter) WriteHeaderNow() {
+	w.setTiming()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timingWriter) Write(data []byte) (int, error) {
+	w.setTiming()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timingWriter) WriteString(s string) (int, error) {
+	w.setTiming()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// serverTiming reports the handler duration in a Server-Timing header, which
+// browser dev tools show next to the request
+func serverTiming() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer = &timingWriter{ResponseWriter: c.Writer, start: time.Now()}
+		c.Next()
+	}
+}
+
+// limitRequestBody rejects request bodies larger than GOELF_MAX_REQUEST_BYTES
+// with 413. A body without a Content-Length is cut off at the limit and the
+// handler reading it gets an *http.MaxBytesError.
+func limitRequestBody() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > config.MaxRequestBytes {
+			respondError(c, http.StatusRequestEntityTooLarge, codePayloadTooLarge, fmt.Sprintf("body exceeds the limit of %d bytes", config.MaxRequestBytes))
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxRequestBytes)
+		}
+		c.Next()
+	}
+}
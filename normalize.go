@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// normalizedLeagues remembers the leagues whose first load was normalized.
+// Changes are logged as info on the first load and as debug afterwards, as
+// the same values would be reported on every fetch.
+var normalizedLeagues sync.Map
+
+// collapseSpace trims a value and collapses runs of whitespace into single
+// spaces, e.g. " Rhein  Fire\t" becomes "Rhein Fire"
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// titleCase upper-cases the first letter of each word and lower-cases the
+// rest, e.g. "RHEIN fire" becomes "Rhein Fire". Words are separated by
+// spaces and hyphens.
+func titleCase(s string) string {
+	runes := []rune(s)
+	start := true
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r) && start:
+			runes[i] = unicode.ToUpper(r)
+			start = false
+		case unicode.IsLetter(r):
+			runes[i] = unicode.ToLower(r)
+		default:
+			start = r == ' ' || r == '-'
+		}
+	}
+	return string(runes)
+}
+
+// normalizeSchedules cleans the string fields of upstream or imported games
+// before they are validated and stored: whitespace is trimmed and collapsed
+// with GOELF_NORMALIZE_WHITESPACE and team names are title-cased with
+// GOELF_TITLE_CASE_TEAMS. Stray whitespace or casing would otherwise miss
+// the division map and split a team in the standings.
+func normalizeSchedules(schedules []Schedule, league string) {
+	if !config.NormalizeWhitespace && !config.TitleCaseTeams {
+		return
+	}
+	_, loaded := normalizedLeagues.LoadOrStore(league, true)
+	logChange := logInfof
+	if loaded {
+		logChange = logDebugf
+	}
+
+	for i := range schedules {
+		s := &schedules[i]
+		fields := []struct {
+			name  string
+			value *string
+			team  bool
+		}{
+			{"statcrewID", &s.StatcrewID, false},
+			{"homename", &s.HomeTeam, true},
+			{"awayname", &s.AwayTeam, true},
+			{"date", &s.Date, false},
+			{"time", &s.Time, false},
+			{"Location", &s.Location, false},
+			{"slug", &s.Slug, false},
+			{"gamedate", &s.GameDate, false},
+		}
+		for _, field := range fields {
+			value := *field.value
+			if config.NormalizeWhitespace {
+				value = collapseSpace(value)
+			}
+			if config.TitleCaseTeams && field.team {
+				value = titleCase(value)
+			}
+			if value != *field.value {
+				logChange("Normalized %s of game %q: %q -> %q", field.name, s.StatcrewID, *field.value, value)
+				*field.value = value
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestCollapseSpace(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{" Rhein  Fire\t", "Rhein Fire"},
+		{" Berlin\nThunder ", "Berlin Thunder"},
+		{"Duisburg", "Duisburg"},
+		{"   ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := collapseSpace(tt.in); got != tt.want {
+			t.Errorf("collapseSpace(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTitleCase(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"RHEIN fire", "Rhein Fire"},
+		{"hamburg sea DEVILS", "Hamburg Sea Devils"},
+		{"FEHERVAR enthroners", "Fehervar Enthroners"},
+		{"münchen-OST", "München-Ost"},
+		{"Rhein Fire", "Rhein Fire"},
+	}
+
+	for _, tt := range tests {
+		if got := titleCase(tt.in); got != tt.want {
+			t.Errorf("titleCase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeSchedules(t *testing.T) {
+	loadTestConfig(t)
+	config.NormalizeWhitespace = true
+	config.TitleCaseTeams = true
+
+	games := append([]Schedule(nil), testGames...)
+	games[0].HomeTeam = "  RHEIN   fire "
+	games[0].Location = " Duisburg\t"
+	games[3].AwayTeam = "rhein FIRE"
+	normalizeSchedules(games, "test")
+
+	if games[0].HomeTeam != "Rhein Fire" || games[3].AwayTeam != "Rhein Fire" {
+		t.Errorf("teams %q and %q, want Rhein Fire", games[0].HomeTeam, games[3].AwayTeam)
+	}
+	if games[0].Location != "Duisburg" {
+		t.Errorf("location %q, want Duisburg", games[0].Location)
+	}
+
+	// Both games count for the same team in its division
+	standing := findStanding(computeStandings(games, teamDivisions), "Rhein Fire", teamDivisions)
+	if standing.Record != "2-0" || standing.Division != "NORTH" {
+		t.Errorf("Rhein Fire %s in %s, want 2-0 in NORTH", standing.Record, standing.Division)
+	}
+}
+
+func TestNormalizeSchedulesDisabled(t *testing.T) {
+	loadTestConfig(t)
+	config.NormalizeWhitespace = false
+	config.TitleCaseTeams = false
+
+	games := []Schedule{{StatcrewID: "g1", HomeTeam: " RHEIN fire", Location: "Duisburg "}}
+	normalizeSchedules(games, "test")
+	if games[0].HomeTeam != " RHEIN fire" || games[0].Location != "Duisburg " {
+		t.Errorf("game %+v changed with normalization disabled", games[0])
+	}
+}
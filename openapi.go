@@ -0,0 +1,48 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPISpec is the hand-written OpenAPI description of the /api endpoints
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// getOpenAPI serves the OpenAPI description, e.g. for Swagger UI or client
+// generators
+func getOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+}
+
+// routeParam matches a gin path parameter like ":id"
+var routeParam = regexp.MustCompile(`:([^/]+)`)
+
+// undocumentedRoutes returns the routes below prefix that have no operation
+// in the OpenAPI description, as "METHOD /path"
+func undocumentedRoutes(routes gin.RoutesInfo, prefix string) ([]string, error) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, prefix+"/") {
+			continue
+		}
+		path := routeParam.ReplaceAllString(strings.TrimPrefix(route.Path, prefix), "{$1}")
+		if _, ok := spec.Paths[path][strings.ToLower(route.Method)]; !ok {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestOpenAPIDocumentsAllRoutes(t *testing.T) {
+	r := newTestRouter(t)
+
+	missing, err := undocumentedRoutes(r.Routes(), "/api/"+apiVersion)
+	if err != nil {
+		t.Fatalf("invalid openapi.json: %v", err)
+	}
+	for _, route := range missing {
+		t.Errorf("%s is missing from openapi.json", route)
+	}
+}
+
+func TestGetOpenAPI(t *testing.T) {
+	r := newTestRouter(t)
+
+	var spec map[string]interface{}
+	decodeResponse(t, serve(r, http.MethodGet, "/api/openapi.json"), &spec)
+	if spec["openapi"] == nil || spec["paths"] == nil {
+		t.Errorf("served spec has no openapi version or paths")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MissingScores lists the games that should have a result but don't
+type MissingScores struct {
+	Count int
+	Games []Schedule // Oldest first
+}
+
+// findMissingScores returns the games whose kickoff plus the game duration
+// has passed but that are still 0-0. Those are most likely results the
+// upstream never delivered and that are missing from the standings. Games
+// without a parseable date can't be judged and are left out.
+func findMissingScores(games []Schedule, now time.Time) []Schedule {
+	missing := []Schedule{}
+	for _, game := range games {
+		if game.HomeScore != 0 || game.AwayScore != 0 {
+			continue
+		}
+		kickoff, ok := parseGameTime(game)
+		if !ok || !now.After(kickoff.Add(config.GameDuration)) {
+			continue
+		}
+		game.HomeLogo = teamLogos[game.HomeTeam]
+		game.AwayLogo = teamLogos[game.AwayTeam]
+		game.Status = gameStatus(game, now)
+		missing = append(missing, game)
+	}
+	sortGamesByDate(missing)
+	return missing
+}
+
+// getMissingScores lists the past games without a result
+func getMissingScores(c *gin.Context) {
+	games, err := loadSchedule(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	missing := findMissingScores(games, time.Now())
+	respondJSON(c, http.StatusOK, MissingScores{Count: len(missing), Games: missing})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Refresh actions
+const (
+	refreshSchedule   = "schedule"
+	refreshScoreboard = "scoreboard"
+	refreshAll        = "all"
+)
+
+// Refresh states
+const (
+	refreshStarted = "started"
+	refreshDone    = "done"
+	refreshFailed  = "failed"
+	refreshSkipped = "skipped" // Nothing is fetched in demo mode
+)
+
+// RefreshResult reports what a refresh did. Without ?wait=true the fetch runs
+// in the background and only the action and "started" are reported.
+type RefreshResult struct {
+	Action     string
+	Status     string                 // started, done, failed or skipped
+	Message    string                 `json:",omitempty"`
+	Schedule   map[string]storeCounts `json:",omitempty"` // Changes of the stored games per league
+	Scoreboard *int                   `json:",omitempty"` // Stored scoreboard entries
+	Errors     []string               `json:",omitempty"` // Failed steps, the details are only logged
+}
+
+// refreshError is the message reported for a failed refresh step. The
+// upstream error may contain URLs and response details, so it is logged
+// instead of returned.
+func refreshError(step string, err error) string {
+	if errors.Is(err, errFetchRunning) {
+		return step + ": a fetch is already running"
+	}
+	logErrorf("Refresh of the %s failed: %v", step, err)
+	return step + ": fetch failed"
+}
+
+// runRefresh performs a refresh action and reports the outcome. "all"
+// fetches the schedule and, if enabled, the scoreboard and recomputes the
+// standings.
+func runRefresh(action string) RefreshResult {
+	result := RefreshResult{Action: action, Status: refreshDone}
+
+	if action == refreshSchedule || action == refreshAll {
+		counts, err := fetchSchedule()
+		result.Schedule = counts
+		if err != nil {
+			result.Errors = append(result.Errors, refreshError(refreshSchedule, err))
+		}
+	}
+	if action == refreshScoreboard || (action == refreshAll && config.FetchScoreboard) {
+		count, err := fetchScoreboard()
+		if err != nil {
+			result.Errors = append(result.Errors, refreshError(refreshScoreboard, err))
+		} else {
+			result.Scoreboard = &count
+		}
+	}
+	if action == refreshAll {
+		invalidateStandings()
+	}
+
+	if len(result.Errors) > 0 {
+		result.Status = refreshFailed
+	}
+	return result
+}
+
+// refreshHandler triggers a refresh action in the background. With
+// ?wait=true it waits for the fetch and reports what it did, responding
+// with 502 if the upstream failed. In demo mode nothing is fetched.
+func refreshHandler(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if action == refreshScoreboard && !config.FetchScoreboard && !config.DemoMode {
+			respondError(c, http.StatusBadRequest, codeBadRequest, "scoreboard fetching is disabled, set GOELF_FETCH_SCOREBOARD=true")
+			return
+		}
+
+		result := RefreshResult{Action: action, Status: refreshStarted}
+		if config.DemoMode {
+			result = RefreshResult{Action: action, Status: refreshSkipped, Message: demoRefreshMessage}
+		} else if c.Query("wait") == "true" {
+			result = runRefresh(action)
+		} else {
+			go runRefresh(action)
+		}
+
+		status := http.StatusOK
+		if result.Status == refreshFailed {
+			status = http.StatusBadGateway
+		}
+
+		// Check if request is from HTMX (has HX-Request header)
+		if wantsHTML(c) {
+			message := "Data refresh initiated"
+			switch result.Status {
+			case refreshDone:
+				message = "Data refreshed"
+			case refreshFailed:
+				message = "Data refresh failed"
+			case refreshSkipped:
+				message = demoRefreshMessage
+			}
+			c.HTML(status, "refresh.html", gin.H{"message": message})
+		} else {
+			c.JSON(status, result)
+		}
+	}
+}
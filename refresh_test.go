@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRefreshHidesUpstreamErrors(t *testing.T) {
+	r := newTestRouter(t)
+	defer func(delay time.Duration) { scheduleRetryDelay = delay }(scheduleRetryDelay)
+	scheduleRetryDelay = time.Millisecond
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal details", http.StatusInternalServerError)
+	}))
+	defer upstream.Close()
+	useScheduleSources(upstream.URL + "/schedule?token=secret")
+
+	w := serve(r, http.MethodGet, "/api/refresh/schedule?wait=true")
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("status %d, want %d", w.Code, http.StatusBadGateway)
+	}
+	var result RefreshResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != refreshFailed || len(result.Errors) != 1 || result.Errors[0] != "schedule: fetch failed" {
+		t.Errorf("result %+v, want the generic schedule failure", result)
+	}
+	if body := w.Body.String(); strings.Contains(body, "secret") || strings.Contains(body, "500") {
+		t.Errorf("response exposes the upstream error: %s", body)
+	}
+}
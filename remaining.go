@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RemainingGame is an upcoming game of a team with its opponent's current
+// standing
+type RemainingGame struct {
+	StatcrewID       string
+	GameWeek         int
+	Date             string
+	Opponent         string
+	Home             bool // The team plays at home
+	OpponentDivision string
+	OpponentRecord   string
+	OpponentWins     int
+	OpponentLosses   int
+	OpponentPosition int  // Rank within the opponent's division, 0 if it isn't listed
+	DivisionGame     bool // Both teams are in the same division
+}
+
+// RemainingSchedule lists the games a team has left
+type RemainingSchedule struct {
+	TeamName string
+	Games    []RemainingGame // Soonest first
+}
+
+// computeRemainingGames returns the games of a team that haven't been played
+// and kick off after now. Games without a parseable date are left out as
+// they can't be placed in the season.
+func computeRemainingGames(games []Schedule, standings []DivisionData, divisions map[string]string, team string, now time.Time) []RemainingGame {
+	var upcoming []Schedule
+	for _, game := range games {
+		if game.HomeTeam != team && game.AwayTeam != team {
+			continue
+		}
+		kickoff, ok := parseGameTime(game)
+		if !ok || !kickoff.After(now) || gamePlayed(game, now) {
+			continue
+		}
+		upcoming = append(upcoming, game)
+	}
+	sortGamesByDate(upcoming)
+
+	division := findStanding(standings, team, divisions).Division
+	remaining := []RemainingGame{}
+	for _, game := range upcoming {
+		entry := RemainingGame{StatcrewID: game.StatcrewID, GameWeek: game.GameWeek, Date: game.Date, Opponent: game.HomeTeam}
+		if game.HomeTeam == team {
+			entry.Opponent = game.AwayTeam
+			entry.Home = true
+		}
+
+		opponent := findStanding(standings, entry.Opponent, divisions)
+		entry.OpponentDivision = opponent.Division
+		entry.OpponentRecord = opponent.Record
+		entry.OpponentWins = opponent.Wins
+		entry.OpponentLosses = opponent.Losses
+		entry.OpponentPosition = opponent.Position
+		entry.DivisionGame = opponent.Division == division && divisions[team] != ""
+		remaining = append(remaining, entry)
+	}
+	return remaining
+}
+
+// getTeamRemaining lists a team's remaining opponents with their records
+func getTeamRemaining(c *gin.Context) {
+	games, err := loadStandingsGames(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	divisions := divisionMap(leagueFromContext(c.Request.Context()))
+	team, ok := findTeam(c.Param("name"), games, divisions)
+	if !ok {
+		respondError(c, http.StatusNotFound, codeNotFound, "team not found")
+		return
+	}
+
+	standings, err := getStandings(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, RemainingSchedule{
+		TeamName: team,
+		Games:    computeRemainingGames(games, standings, divisions, team, time.Now()),
+	})
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Response formats offered through content negotiation
+const (
+	mimeJSON     = "application/json"
+	mimeCSV      = "text/csv"
+	mimeCalendar = "text/calendar"
+)
+
+// negotiateFormat picks the best of the offered formats for the Accept
+// header. JSON is used when the header is missing or nothing matches.
+func negotiateFormat(c *gin.Context, offered ...string) string {
+	if format := c.NegotiateFormat(offered...); format != "" {
+		return format
+	}
+	return mimeJSON
+}
+
+// respondJSON writes a JSON response, indented when the request has
+// ?pretty=true
+func respondJSON(c *gin.Context, status int, obj interface{}) {
+	if c.Query("pretty") == "true" {
+		c.IndentedJSON(status, obj)
+		return
+	}
+	c.JSON(status, obj)
+}
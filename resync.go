@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// resyncSchedule downloads the whole schedule of every league regardless of
+// the stored ETag and Last-Modified and rewrites every game that differs
+// from the upstream. The regular fetches skip unchanged responses, so a
+// historical result the upstream corrects without changing its validators
+// would otherwise never be picked up. It waits for a running fetch instead
+// of skipping.
+func resyncSchedule() {
+	scheduleFetchMu.Lock()
+	defer scheduleFetchMu.Unlock()
+
+	var errs []error
+	for _, league := range config.Leagues {
+		logInfof("Full re-sync: downloading the complete schedule of league %s", league.ID)
+		counts, err := updateSchedule(league, true)
+		if err != nil {
+			logErrorf("Full re-sync of league %s failed: %v", league.ID, err)
+			errs = append(errs, fmt.Errorf("%s: %w", league.ID, err))
+			continue
+		}
+		logInfof("Full re-sync of league %s finished: %d inserted, %d updated, %d removed, %d unchanged",
+			league.ID, counts.Inserted, counts.Updated, counts.Removed, counts.Unchanged)
+	}
+	recordFetchResult(errors.Join(errs...))
+
+	if config.FetchScoreboard {
+		fetchScoreboard()
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"strconv"
+)
+
+// seasonDated matches the games whose date starts with a year. Games with
+// an empty or malformed date have no season and are never removed.
+const seasonDated = "date GLOB '[0-9][0-9][0-9][0-9]*'"
+
+// cleanupOldSeasons deletes games from seasons older than the configured
+// number of seasons to keep. A season is the calendar year of the game date.
+// Each league keeps its own latest stored season, so a league that is
+// already playing the next season doesn't remove another league's current
+// one.
+func cleanupOldSeasons() {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	latestSeasons, err := latestSeasonByLeague(ctx)
+	if err != nil {
+		logErrorf("Error finding latest seasons: %v", err)
+		return
+	}
+	if len(latestSeasons) == 0 {
+		logInfof("Retention cleanup: no seasons stored, nothing to remove")
+		return
+	}
+
+	var removed int64
+	for league, latestSeason := range latestSeasons {
+		cutoff := latestSeason - config.RetentionSeasons + 1
+		result, err := db.ExecContext(ctx, "DELETE FROM schedule WHERE league = ? AND "+seasonDated+" AND substr(date, 1, 4) < ?", league, strconv.Itoa(cutoff))
+		if err != nil {
+			logErrorf("Error removing old seasons of league %s: %v", league, err)
+			continue
+		}
+		leagueRemoved, _ := result.RowsAffected()
+		removed += leagueRemoved
+		logInfof("Retention cleanup: removed %d games of league %s from seasons before %d", leagueRemoved, league, cutoff)
+	}
+
+	// Box scores and score history of removed games are no longer reachable
+	for _, table := range []string{"boxscore", "score_history"} {
+		if _, err := db.ExecContext(ctx, "DELETE FROM "+table+" WHERE statcrew_id NOT IN (SELECT statcrew_id FROM schedule)"); err != nil {
+			logErrorf("Error removing old %s rows: %v", table, err)
+		}
+	}
+	if removed > 0 {
+		invalidateStandings()
+	}
+}
+
+// latestSeasonByLeague returns the latest stored season of every league
+func latestSeasonByLeague(ctx context.Context) (map[string]int, error) {
+	rows, err := db.QueryContext(ctx, "SELECT league, MAX(substr(date, 1, 4)) FROM schedule WHERE "+seasonDated+" GROUP BY league")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seasons := make(map[string]int)
+	for rows.Next() {
+		var league, latest string
+		if err := rows.Scan(&league, &latest); err != nil {
+			return nil, err
+		}
+		season, err := strconv.Atoi(latest)
+		if err != nil {
+			continue
+		}
+		seasons[league] = season
+	}
+	return seasons, rows.Err()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+func TestCleanupOldSeasons(t *testing.T) {
+	newTestRouter(t)
+	config.RetentionSeasons = 2
+
+	seedGames(t, []Schedule{
+		mockGame("elf-2022", 1, "2022-06-04", "15:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 21, 7),
+		mockGame("elf-2023", 1, "2023-06-03", "15:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 14, 7),
+		mockGame("elf-2024", 1, "2024-06-01", "15:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 28, 14),
+		{StatcrewID: "elf-undated", HomeTeam: "Rhein Fire", AwayTeam: "Nordic Storm"},
+		{StatcrewID: "elf-tbd", HomeTeam: "Rhein Fire", AwayTeam: "Hamburg Sea Devils", Date: "TBD"},
+	})
+	// A second league that hasn't started its 2024 season yet
+	youth := []Schedule{
+		mockGame("youth-2021", 1, "2021-06-05", "11:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 10, 3),
+		mockGame("youth-2022", 1, "2022-06-04", "11:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 17, 3),
+		mockGame("youth-2023", 1, "2023-06-03", "11:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 20, 6),
+	}
+	if _, err := storeSchedules(withLeague(context.Background(), "youth"), youth, false); err != nil {
+		t.Fatalf("storeSchedules: %v", err)
+	}
+
+	cleanupOldSeasons()
+
+	rows, err := db.Query("SELECT statcrew_id FROM schedule")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var kept []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		kept = append(kept, id)
+	}
+	sort.Strings(kept)
+
+	want := []string{"elf-2023", "elf-2024", "elf-tbd", "elf-undated", "youth-2022", "youth-2023"}
+	if len(kept) != len(want) {
+		t.Fatalf("kept %v, want %v", kept, want)
+	}
+	for i := range want {
+		if kept[i] != want[i] {
+			t.Fatalf("kept %v, want %v", kept, want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scenario states
+const (
+	scenarioClinched   = "clinched"
+	scenarioEliminated = "eliminated"
+	scenarioAlive      = "alive"
+)
+
+// Scenario tells what a team needs for one goal, e.g. winning its division
+type Scenario struct {
+	Status string // clinched, eliminated or alive
+	// MagicNumber is the combination of own wins and Rival losses that
+	// clinches, nil unless alive. Only set for the division.
+	MagicNumber *int   `json:",omitempty"`
+	Rival       string `json:",omitempty"` // The rival that determines the magic number
+	// WinsNeeded is how many of its remaining games the team has to win to
+	// clinch whatever the others do, nil if winning out isn't enough
+	WinsNeeded  *int `json:",omitempty"`
+	Description string
+}
+
+// TeamScenarios is what a team needs to clinch its division and a playoff
+// spot. It is based on wins and the remaining games only, tiebreakers are
+// ignored and ties can't clinch.
+type TeamScenarios struct {
+	TeamName       string
+	Division       string
+	Record         string
+	GamesRemaining int
+	DivisionTitle  Scenario
+	PlayoffSpot    Scenario
+}
+
+// allStandings returns the standing of every team of the league, including
+// the teams of its division map that haven't played yet
+func allStandings(standings []DivisionData, divisions map[string]string) []TeamStanding {
+	seen := make(map[string]bool)
+	var teams []TeamStanding
+	for _, division := range standings {
+		for _, team := range division.Teams {
+			seen[normalizeTeamName(team.TeamName)] = true
+			teams = append(teams, team)
+		}
+	}
+	for _, team := range knownTeams(divisions) {
+		if normalized := normalizeTeamName(team); !seen[normalized] {
+			seen[normalized] = true
+			teams = append(teams, findStanding(standings, team, divisions))
+		}
+	}
+	return teams
+}
+
+// sameTeam reports whether two standings belong to the same team, whatever
+// the spelling
+func sameTeam(a, b TeamStanding) bool {
+	return normalizeTeamName(a.TeamName) == normalizeTeamName(b.TeamName)
+}
+
+// maxWins is the win total of a team that wins all remaining games
+func maxWins(team TeamStanding) int {
+	return team.Wins + team.GamesRemaining
+}
+
+// winsPhrase describes winning some of the remaining games
+func winsPhrase(wins, remaining int) string {
+	if wins == remaining {
+		return "Win out"
+	}
+	return fmt.Sprintf("Win %d of %d remaining games", wins, remaining)
+}
+
+// divisionScenario computes the magic number of a team for its division. The
+// team clinches once no rival can reach its win total, consistent with the
+// Clinched flag of the standings.
+func divisionScenario(team TeamStanding, teams []TeamStanding) Scenario {
+	if team.Clinched {
+		return Scenario{Status: scenarioClinched, Description: "Clinched the division"}
+	}
+
+	magic, rival, leaderWins := 0, TeamStanding{}, 0
+	for _, other := range teams {
+		if sameTeam(other, team) || other.Division != team.Division {
+			continue
+		}
+		if other.Wins > leaderWins {
+			leaderWins = other.Wins
+		}
+		// Each own win and each loss of the rival brings it down by one
+		if m := maxWins(other) - team.Wins + 1; m > magic {
+			magic, rival = m, other
+		}
+	}
+	if maxWins(team) < leaderWins {
+		return Scenario{Status: scenarioEliminated, Description: "Eliminated from the division title"}
+	}
+	if magic <= 0 {
+		return Scenario{Status: scenarioClinched, Description: "Clinched the division"}
+	}
+
+	scenario := Scenario{Status: scenarioAlive, MagicNumber: &magic, Rival: rival.TeamName}
+	if magic <= team.GamesRemaining {
+		scenario.WinsNeeded = &magic
+		scenario.Description = fmt.Sprintf("%s, or any combination of %d wins and %s losses", winsPhrase(magic, team.GamesRemaining), magic, rival.TeamName)
+		if magic == 1 {
+			scenario.Description = fmt.Sprintf("%s, or a %s loss", winsPhrase(magic, team.GamesRemaining), rival.TeamName)
+		}
+		return scenario
+	}
+
+	// Winning out isn't enough, the rivals have to lose too
+	scenario.Description = fmt.Sprintf("Win out and %s", rivalLosses(team, teams))
+	return scenario
+}
+
+// rivalLosses describes the losses the division rivals need for a team that
+// wins out, e.g. "Rhein Fire loses 2 more games"
+func rivalLosses(team TeamStanding, teams []TeamStanding) string {
+	description := ""
+	for _, other := range teams {
+		if sameTeam(other, team) || other.Division != team.Division {
+			continue
+		}
+		losses := maxWins(other) - maxWins(team) + 1
+		if losses <= 0 {
+			continue
+		}
+		if description != "" {
+			description += " and "
+		}
+		if losses == 1 {
+			description += fmt.Sprintf("%s loses 1 more game", other.TeamName)
+		} else {
+			description += fmt.Sprintf("%s loses %d more games", other.TeamName, losses)
+		}
+	}
+	return description
+}
+
+// playoffThreats counts the teams that could still finish ahead of a team
+// with the given win total: the teams that can reach it, plus one for every
+// other division of the league without such a team, since its winner may
+// take an automatic spot. This is conservative, a team is only reported as
+// clinched when it can't miss the playoffs.
+func playoffThreats(team TeamStanding, teams []TeamStanding, wins int) int {
+	threats := 0
+	threatened := make(map[string]bool)
+	for _, other := range teams {
+		if sameTeam(other, team) || maxWins(other) < wins {
+			continue
+		}
+		threats++
+		threatened[other.Division] = true
+	}
+	if config.PlayoffTeams > config.PlayoffWildcards {
+		for _, other := range teams {
+			if other.Division != team.Division && !threatened[other.Division] {
+				threatened[other.Division] = true
+				threats++
+			}
+		}
+	}
+	return threats
+}
+
+// playoffScenario computes how many wins guarantee a team a playoff spot
+func playoffScenario(team TeamStanding, teams []TeamStanding) Scenario {
+	if playoffThreats(team, teams, team.Wins) < config.PlayoffTeams {
+		return Scenario{Status: scenarioClinched, Description: "Clinched a playoff spot"}
+	}
+
+	// Teams that already have more wins than the team can reach finish
+	// ahead of it in any case
+	ahead := 0
+	for _, other := range teams {
+		if !sameTeam(other, team) && other.Wins > maxWins(team) {
+			ahead++
+		}
+	}
+	if ahead >= config.PlayoffTeams {
+		return Scenario{Status: scenarioEliminated, Description: "Eliminated from the playoffs"}
+	}
+
+	for wins := 1; wins <= team.GamesRemaining; wins++ {
+		if playoffThreats(team, teams, team.Wins+wins) < config.PlayoffTeams {
+			return Scenario{Status: scenarioAlive, WinsNeeded: &wins, Description: winsPhrase(wins, team.GamesRemaining)}
+		}
+	}
+	return Scenario{Status: scenarioAlive, Description: "Needs help from other results"}
+}
+
+// getTeamScenarios returns what a team needs to clinch its division and a
+// playoff spot
+func getTeamScenarios(c *gin.Context) {
+	games, err := loadSchedule(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	divisions := divisionMap(leagueFromContext(c.Request.Context()))
+	name, ok := findTeam(c.Param("name"), games, divisions)
+	if !ok {
+		respondError(c, http.StatusNotFound, codeNotFound, "team not found")
+		return
+	}
+
+	standings, err := getStandings(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	teams := allStandings(standings, divisions)
+	team := findStanding(standings, name, divisions)
+	respondJSON(c, http.StatusOK, TeamScenarios{
+		TeamName:       team.TeamName,
+		Division:       team.Division,
+		Record:         team.Record,
+		GamesRemaining: team.GamesRemaining,
+		DivisionTitle:  divisionScenario(team, teams),
+		PlayoffSpot:    playoffScenario(team, teams),
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// seasonLookahead is how far ahead a scheduled game keeps the fetcher in
+// active mode
+const seasonLookahead = 7 * 24 * time.Hour
+
+// fetchScheduler runs the fetch job on the active season schedule while
+// games are coming up and on the slower offseason schedule otherwise
+type fetchScheduler struct {
+	mu        sync.Mutex
+	cron      *cron.Cron
+	job       func()
+	entry     cron.EntryID
+	scheduled bool
+	offseason bool
+}
+
+// start registers the job on the active season schedule
+func (s *fetchScheduler) start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.schedule(false)
+}
+
+// update switches between the active and offseason schedules depending on
+// whether a game is live or starts within the next week
+func (s *fetchScheduler) update() {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	// Any league with games coming up keeps the active schedule
+	offseason := true
+	for _, league := range config.Leagues {
+		games, err := loadSchedule(withLeague(ctx, league.ID))
+		if err != nil {
+			logErrorf("Error loading schedule for the fetch interval: %v", err)
+			return
+		}
+		if hasUpcomingGames(games, time.Now()) {
+			offseason = false
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if offseason == s.offseason {
+		return
+	}
+	if err := s.schedule(offseason); err != nil {
+		logErrorf("Error changing the fetch schedule: %v", err)
+		return
+	}
+	if offseason {
+		logInfof("No games within the next week, fetching on the offseason schedule (%s)", config.OffseasonFetchCron)
+	} else {
+		logInfof("Games coming up, fetching on the active season schedule (%s)", config.FetchCron)
+	}
+}
+
+// schedule replaces the cron entry of the job. The caller must hold s.mu.
+func (s *fetchScheduler) schedule(offseason bool) error {
+	spec := config.FetchCron
+	if offseason {
+		spec = config.OffseasonFetchCron
+	}
+
+	entry, err := s.cron.AddFunc(spec, s.job)
+	if err != nil {
+		return err
+	}
+	if s.scheduled {
+		s.cron.Remove(s.entry)
+	}
+	s.entry = entry
+	s.scheduled = true
+	s.offseason = offseason
+	recordFetchInterval(cronInterval(spec))
+	return nil
+}
+
+// cronInterval returns the time between the next two runs of a cron spec
+func cronInterval(spec string) time.Duration {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return 0
+	}
+	next := schedule.Next(time.Now())
+	return schedule.Next(next).Sub(next)
+}
+
+// hasUpcomingGames reports whether a game is in progress or kicks off within
+// seasonLookahead
+func hasUpcomingGames(games []Schedule, now time.Time) bool {
+	for _, game := range games {
+		kickoff, ok := parseGameTime(game)
+		if !ok {
+			continue
+		}
+		if kickoff.After(now.Add(-config.GameDuration)) && kickoff.Before(now.Add(seasonLookahead)) {
+			return true
+		}
+	}
+	return false
+}
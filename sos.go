@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SoSGame is a played game that counts towards a team's strength of schedule
+type SoSGame struct {
+	StatcrewID     string
+	GameWeek       int
+	Date           string
+	Opponent       string
+	Home           bool   // The team played at home
+	Result         string // W, L or T from the team's view
+	OpponentWins   int    // Opponent wins, without its games against the team
+	OpponentLosses int    // Opponent losses, without its games against the team
+	OpponentRecord string
+	CountsForSoV   bool // Won games also make up the strength of victory
+}
+
+// SoSBreakdown shows which opponents make up a team's SoS and SoV
+type SoSBreakdown struct {
+	TeamName string
+	SoS      float64
+	SoV      float64
+	Games    []SoSGame // Oldest first
+}
+
+// computeSoSBreakdown lists the games behind a team's SoS and SoV, using the
+// same opponent records as computeStandings
+func computeSoSBreakdown(games []Schedule, divisions map[string]string, team string) SoSBreakdown {
+	played := playedGames(games)
+	teamStats, headToHead, _ := tallyGames(played, divisions)
+
+	breakdown := SoSBreakdown{TeamName: team, Games: []SoSGame{}}
+	var opponentWins, opponentLosses, defeatedWins, defeatedLosses int
+	for _, game := range played {
+		entry := SoSGame{StatcrewID: game.StatcrewID, GameWeek: game.GameWeek, Date: game.Date}
+		teamScore, opponentScore := game.HomeScore, game.AwayScore
+		switch team {
+		case game.HomeTeam:
+			entry.Opponent = game.AwayTeam
+			entry.Home = true
+		case game.AwayTeam:
+			entry.Opponent = game.HomeTeam
+			teamScore, opponentScore = opponentScore, teamScore
+		default:
+			continue
+		}
+
+		switch {
+		case teamScore > opponentScore:
+			entry.Result = "W"
+			entry.CountsForSoV = true
+		case teamScore < opponentScore:
+			entry.Result = "L"
+		default:
+			entry.Result = "T"
+		}
+
+		entry.OpponentWins, entry.OpponentLosses = opponentRecord(teamStats, headToHead, team, entry.Opponent)
+		entry.OpponentRecord = fmt.Sprintf("%d-%d", entry.OpponentWins, entry.OpponentLosses)
+		opponentWins += entry.OpponentWins
+		opponentLosses += entry.OpponentLosses
+		if entry.CountsForSoV {
+			defeatedWins += entry.OpponentWins
+			defeatedLosses += entry.OpponentLosses
+		}
+		breakdown.Games = append(breakdown.Games, entry)
+	}
+
+	if total := opponentWins + opponentLosses; total > 0 {
+		breakdown.SoS = float64(opponentWins) / float64(total)
+	}
+	if total := defeatedWins + defeatedLosses; total > 0 {
+		breakdown.SoV = float64(defeatedWins) / float64(total)
+	}
+	return breakdown
+}
+
+// getTeamSoS returns the breakdown of a team's strength of schedule
+func getTeamSoS(c *gin.Context) {
+	games, err := loadStandingsGames(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	divisions := divisionMap(leagueFromContext(c.Request.Context()))
+	team, ok := findTeam(c.Param("name"), games, divisions)
+	if !ok {
+		respondError(c, http.StatusNotFound, codeNotFound, "team not found")
+		return
+	}
+
+	respondJSON(c, http.StatusOK, computeSoSBreakdown(games, divisions, team))
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestStandingsSoSAndSoV(t *testing.T) {
+	loadTestConfig(t)
+
+	// Records in testGames: Rhein Fire 2-0, Berlin Thunder 1-1, Nordic Storm
+	// 1-1, Hamburg Sea Devils 0-2. Without its game against the team, every
+	// opponent record changes, e.g. Rhein Fire's opponents are 1-0 each
+	// instead of 1-1.
+	tests := []struct {
+		team    string
+		wantSoS float64
+		wantSoV float64
+	}{
+		{"Rhein Fire", 1, 1},       // Berlin 1-0, Nordic 1-0
+		{"Berlin Thunder", 0.5, 0}, // Rhein Fire 1-0, Hamburg 0-1
+		{"Nordic Storm", 0.5, 0},   // Hamburg 0-1, Rhein Fire 1-0
+		{"Hamburg Sea Devils", 0, 0},
+	}
+
+	standings := computeStandings(testGames, teamDivisions)
+	for _, tt := range tests {
+		t.Run(tt.team, func(t *testing.T) {
+			standing := findStanding(standings, tt.team, teamDivisions)
+			if standing.SoS != tt.wantSoS || standing.SoV != tt.wantSoV {
+				t.Errorf("SoS %v SoV %v, want %v %v", standing.SoS, standing.SoV, tt.wantSoS, tt.wantSoV)
+			}
+		})
+	}
+}
+
+func TestSoSRepeatedOpponent(t *testing.T) {
+	loadTestConfig(t)
+
+	// Rhein Fire beat Berlin twice, Berlin beat Hamburg once: excluding both
+	// head-to-head games leaves Berlin 1-0
+	games := []Schedule{
+		mockGame("g1", 1, "2024-05-18", "15:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 28, 14),
+		mockGame("g2", 2, "2024-05-25", "15:00", "Berlin Thunder", "Hamburg Sea Devils", "Berlin", 17, 10),
+		mockGame("g3", 3, "2024-06-01", "15:00", "Berlin Thunder", "Rhein Fire", "Berlin", 3, 24),
+	}
+	if standing := findStanding(computeStandings(games, teamDivisions), "Rhein Fire", teamDivisions); standing.SoS != 1 {
+		t.Errorf("Rhein Fire SoS %v, want 1", standing.SoS)
+	}
+}
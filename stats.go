@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startTime is when the process started, for the uptime
+var startTime = time.Now()
+
+// Process counters reported by /api/stats
+var (
+	requestsServed atomic.Int64
+	fetchSuccesses atomic.Int64
+	fetchFailures  atomic.Int64
+)
+
+// statsTables are the tables whose row counts /api/stats reports
+var statsTables = []string{"schedule", "scoreboard", "boxscore", "score_history", "teams"}
+
+// countRequests counts every request handled by the router
+func countRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestsServed.Add(1)
+		c.Next()
+	}
+}
+
+// getStats returns process counters and the row count of each table
+func getStats(c *gin.Context) {
+	rows := make(map[string]int64, len(statsTables))
+	for _, table := range statsTables {
+		var count int64
+		if err := db.QueryRowContext(c.Request.Context(), "SELECT COUNT(*) FROM "+table).Scan(&count); err != nil {
+			respondDBError(c, err)
+			return
+		}
+		rows[table] = count
+	}
+
+	uptime := time.Since(startTime)
+	c.JSON(http.StatusOK, gin.H{
+		"uptime":         uptime.Round(time.Second).String(),
+		"uptimeSeconds":  int64(uptime.Seconds()),
+		"requestsServed": requestsServed.Load(),
+		"fetchSuccesses": fetchSuccesses.Load(),
+		"fetchFailures":  fetchFailures.Load(),
+		"rows":           rows,
+	})
+}
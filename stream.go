@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamFlushEvery is how many games are written between two flushes
+const streamFlushEvery = 100
+
+// streamErrorTrailer reports an error that happened after the response
+// started, when the status can't be changed anymore. Like respondDBError it
+// only carries a fixed message, the error itself is logged.
+const streamErrorTrailer = "X-Stream-Error"
+
+// getScheduleStream writes the stored games of the league as a JSON array,
+// one row at a time, so large exports don't have to fit in memory. Dates are
+// left as stored and games are in database order, so undated games aren't
+// moved to the end. If reading fails midway the array is still closed and
+// "database error" is sent in the X-Stream-Error trailer.
+func getScheduleStream(c *gin.Context) {
+	sortKey := config.DefaultSort
+	if sortKey == "" {
+		sortKey = "date"
+	}
+	orderBy, ok := scheduleSortOrders[c.DefaultQuery("sort", sortKey)]
+	if !ok {
+		respondError(c, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("unknown sort %q", c.Query("sort")))
+		return
+	}
+
+	ctx := c.Request.Context()
+	rows, err := db.QueryContext(ctx, "SELECT "+scheduleColumns+" FROM schedule WHERE league = ? ORDER BY "+orderBy, leagueFromContext(ctx))
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+	defer rows.Close()
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Header("Trailer", streamErrorTrailer)
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	encoder := json.NewEncoder(w)
+	now := time.Now()
+	count := 0
+	w.WriteString("[")
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			logErrorf("Error scanning schedule: %v", err)
+			continue
+		}
+		if config.UndatedGames == undatedExclude {
+			if _, ok := parseGameTime(s); !ok {
+				continue
+			}
+		}
+		s.HomeLogo = teamLogos[s.HomeTeam]
+		s.AwayLogo = teamLogos[s.AwayTeam]
+		s.Status = gameStatus(s, now)
+
+		if count > 0 {
+			w.WriteString(",")
+		}
+		if err := encoder.Encode(s); err != nil {
+			// The client went away, nothing more can be written
+			logWarnf("Schedule stream aborted after %d games: %v", count, err)
+			return
+		}
+		count++
+		if count%streamFlushEvery == 0 {
+			w.Flush()
+		}
+	}
+	w.WriteString("]\n")
+
+	if err := rows.Err(); err != nil {
+		logErrorf("Schedule stream failed after %d games: %v", count, err)
+		w.Header().Set(streamErrorTrailer, "database error")
+	}
+}
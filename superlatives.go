@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// closestGamesCount is the number of games in Superlatives.ClosestGames
+const closestGamesCount = 3
+
+// Superlatives are the record games of the stored season. The single games
+// are nil and ClosestGames is empty until a game has been played.
+type Superlatives struct {
+	HighestScoring *GameDetail  // Most combined points
+	BiggestBlowout *GameDetail  // Largest margin
+	ClosestGames   []GameDetail // Smallest margins, closest first
+}
+
+// computeSuperlatives finds the record games among the played games. Ties
+// go to the game that set the record first.
+func computeSuperlatives(games []Schedule) Superlatives {
+	superlatives := Superlatives{ClosestGames: []GameDetail{}}
+
+	// One point in time for both the played check and the details, so a game
+	// finishing in between can't end up without a margin
+	now := time.Now()
+	var details []GameDetail // Oldest first, like the played games
+	for _, game := range playedGamesAt(games, now) {
+		game.HomeLogo = teamLogos[game.HomeTeam]
+		game.AwayLogo = teamLogos[game.AwayTeam]
+		game.Status = gameStatus(game, now)
+		if detail := newGameDetail(game, now); detail.Margin != nil {
+			details = append(details, detail)
+		}
+	}
+	if len(details) == 0 {
+		return superlatives
+	}
+
+	// Only a strictly bigger value replaces a record, so the earlier game
+	// keeps it on a tie
+	highest, blowout := 0, 0
+	for i, detail := range details {
+		if combinedScore(detail) > combinedScore(details[highest]) {
+			highest = i
+		}
+		if *detail.Margin > *details[blowout].Margin {
+			blowout = i
+		}
+	}
+	superlatives.HighestScoring = &details[highest]
+	superlatives.BiggestBlowout = &details[blowout]
+
+	// The stable sort keeps games with the same margin in chronological order
+	closest := make([]GameDetail, len(details))
+	copy(closest, details)
+	sort.SliceStable(closest, func(i, j int) bool {
+		return *closest[i].Margin < *closest[j].Margin
+	})
+	if len(closest) > closestGamesCount {
+		closest = closest[:closestGamesCount]
+	}
+	superlatives.ClosestGames = closest
+	return superlatives
+}
+
+// combinedScore is the total points of a game
+func combinedScore(detail GameDetail) int {
+	return detail.HomeScore + detail.AwayScore
+}
+
+// getSuperlatives returns the highest scoring game, the biggest blowout and
+// the closest games
+func getSuperlatives(c *gin.Context) {
+	games, err := loadStandingsGames(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	respondJSON(c, http.StatusOK, computeSuperlatives(games))
+}
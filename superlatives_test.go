@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestComputeSuperlatives(t *testing.T) {
+	loadTestConfig(t)
+
+	// Listed newest first to check that ties go by date, not by input order
+	games := []Schedule{
+		mockGame("g5", 5, "2099-06-15", "15:00", "Rhein Fire", "Nordic Storm", "Duisburg", 0, 0),
+		mockGame("g4", 4, "2024-06-08", "15:00", "Berlin Thunder", "Hamburg Sea Devils", "Berlin", 10, 7), // 17 points, margin 3
+		mockGame("g3", 3, "2024-06-01", "15:00", "Rhein Fire", "Nordic Storm", "Duisburg", 24, 18),        // 42 points, margin 6
+		mockGame("g2", 2, "2024-05-25", "15:00", "Nordic Storm", "Hamburg Sea Devils", "Herning", 21, 7),  // 28 points, margin 14
+		mockGame("g1", 1, "2024-05-18", "15:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 28, 14),      // 42 points, margin 14
+	}
+
+	superlatives := computeSuperlatives(games)
+	if got := superlatives.HighestScoring; got == nil || got.StatcrewID != "g1" {
+		t.Errorf("highest scoring %v, want g1 which reached 42 points first", got)
+	}
+	if got := superlatives.BiggestBlowout; got == nil || got.StatcrewID != "g1" {
+		t.Errorf("biggest blowout %v, want g1 which reached a margin of 14 first", got)
+	}
+
+	want := []string{"g4", "g3", "g1"}
+	if len(superlatives.ClosestGames) != len(want) {
+		t.Fatalf("got %d closest games, want %d", len(superlatives.ClosestGames), len(want))
+	}
+	for i, detail := range superlatives.ClosestGames {
+		if detail.StatcrewID != want[i] {
+			t.Errorf("closest game %d is %s, want %s", i+1, detail.StatcrewID, want[i])
+		}
+	}
+}
+
+func TestComputeSuperlativesNoGamesPlayed(t *testing.T) {
+	loadTestConfig(t)
+
+	superlatives := computeSuperlatives(testGames[4:]) // The unplayed week 3
+	if superlatives.HighestScoring != nil || superlatives.BiggestBlowout != nil {
+		t.Errorf("got record games %+v before any game was played", superlatives)
+	}
+	if superlatives.ClosestGames == nil || len(superlatives.ClosestGames) != 0 {
+		t.Errorf("closest games %v, want an empty list", superlatives.ClosestGames)
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recentResultsCount is the number of played games shown in a team profile
+const recentResultsCount = 5
+
+// Team search result limits
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// maxCompareTeams is the most teams /api/compare accepts
+const maxCompareTeams = 8
+
+// TeamSearchResult is a team matching a search query
+type TeamSearchResult struct {
+	TeamName string
+	Division string
+	Logo     string
+	Color    string `json:",omitempty"`
+}
+
+// TeamProfile is the full view of a single team
+type TeamProfile struct {
+	TeamStanding
+	RecentResults []GameDetail // Most recent first
+	UpcomingGames []Schedule   // Next game first
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "ő", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u", "ű", "u",
+	"ł", "l", "ß", "ss",
+)
+
+// normalizeTeamName makes team names comparable regardless of casing,
+// accents and surrounding whitespace ("Fehérvár" matches "fehervar")
+func normalizeTeamName(name string) string {
+	name = accentReplacer.Replace(strings.ToLower(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// findTeam resolves a team name to the spelling used in the schedule, or in
+// the league's division map if the team hasn't appeared in any game yet
+func findTeam(name string, games []Schedule, divisions map[string]string) (string, bool) {
+	normalized := normalizeTeamName(name)
+	if normalized == "" {
+		return "", false
+	}
+
+	for _, game := range games {
+		if normalizeTeamName(game.HomeTeam) == normalized {
+			return game.HomeTeam, true
+		}
+		if normalizeTeamName(game.AwayTeam) == normalized {
+			return game.AwayTeam, true
+		}
+	}
+
+	for team := range divisions {
+		if normalizeTeamName(team) == normalized {
+			return team, true
+		}
+	}
+	return "", false
+}
+
+// findStanding returns the standing of a team, or an empty 0-0 standing in
+// its division of the league's map if the team hasn't played yet
+func findStanding(standings []DivisionData, team string, divisions map[string]string) TeamStanding {
+	for _, division := range standings {
+		for _, standing := range division.Teams {
+			if standing.TeamName == team {
+				return standing
+			}
+		}
+	}
+
+	division := divisions[team]
+	if division == "" {
+		division = "UNKNOWN"
+	}
+	return TeamStanding{
+		TeamName:       team,
+		Division:       division,
+		Record:         "0-0",
+		Logo:           teamLogos[team],
+		Color:          teamColors[team],
+		DivRecord:      "0-0",
+		GamesRemaining: gamesRemaining(0),
+	}
+}
+
+func getTeam(c *gin.Context) {
+	games, err := loadSchedule(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	divisions := divisionMap(leagueFromContext(c.Request.Context()))
+	team, ok := findTeam(c.Param("name"), games, divisions)
+	if !ok {
+		respondError(c, http.StatusNotFound, codeNotFound, "team not found")
+		return
+	}
+
+	standings, err := getStandings(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	profile := TeamProfile{
+		TeamStanding:  findStanding(standings, team, divisions),
+		RecentResults: []GameDetail{},
+		UpcomingGames: []Schedule{},
+	}
+
+	// Games are ordered by date, so walk backwards for the recent results
+	now := time.Now()
+	for i := len(games) - 1; i >= 0; i-- {
+		game := games[i]
+		if game.HomeTeam != team && game.AwayTeam != team {
+			continue
+		}
+		game.HomeLogo = teamLogos[game.HomeTeam]
+		game.AwayLogo = teamLogos[game.AwayTeam]
+		game.Status = gameStatus(game, now)
+
+		if gamePlayed(game, now) {
+			if len(profile.RecentResults) < recentResultsCount {
+				profile.RecentResults = append(profile.RecentResults, newGameDetail(game, now))
+			}
+		} else {
+			profile.UpcomingGames = append([]Schedule{game}, profile.UpcomingGames...)
+		}
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// knownTeams returns the teams of a division map sorted by name. Spellings
+// that normalize to the same name (with and without accents) are only
+// listed once.
+func knownTeams(divisions map[string]string) []string {
+	var teams []string
+	for team := range divisions {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+
+	seen := make(map[string]bool)
+	unique := teams[:0]
+	for _, team := range teams {
+		normalized := normalizeTeamName(team)
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		unique = append(unique, team)
+	}
+	return unique
+}
+
+// searchRank scores how well a normalized team name matches the query, lower
+// is better. Names that don't contain the query return -1.
+func searchRank(name, query string) int {
+	switch {
+	case name == query:
+		return 0
+	case strings.HasPrefix(name, query):
+		return 1
+	case strings.Contains(name, " "+query):
+		return 2 // Starts a later word, e.g. "fire" in "rhein fire"
+	case strings.Contains(name, query):
+		return 3
+	default:
+		return -1
+	}
+}
+
+// searchTeams returns the known teams containing the query, best match first
+func searchTeams(c *gin.Context) {
+	query := normalizeTeamName(c.Query("q"))
+	if query == "" {
+		respondError(c, http.StatusBadRequest, codeBadRequest, "query parameter q is required")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			respondError(c, http.StatusBadRequest, codeBadRequest, "limit must be a positive number")
+			return
+		}
+	}
+	limit = clampLimit(c, limit, maxSearchLimit)
+
+	type match struct {
+		team string
+		rank int
+	}
+	divisions := divisionMap(leagueFromContext(c.Request.Context()))
+	var matches []match
+	for _, team := range knownTeams(divisions) {
+		if rank := searchRank(normalizeTeamName(team), query); rank >= 0 {
+			matches = append(matches, match{team, rank})
+		}
+	}
+
+	// knownTeams is sorted by name, keep that order within a rank
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].rank < matches[j].rank
+	})
+
+	results := []TeamSearchResult{}
+	for _, m := range matches {
+		if len(results) >= limit {
+			break
+		}
+		results = append(results, TeamSearchResult{
+			TeamName: m.team,
+			Division: divisions[m.team],
+			Logo:     teamLogos[m.team],
+			Color:    teamColors[m.team],
+		})
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// compareTeams returns the standings of the teams in ?teams=A,B in the
+// requested order
+func compareTeams(c *gin.Context) {
+	var names []string
+	for _, name := range strings.Split(c.Query("teams"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		respondError(c, http.StatusBadRequest, codeBadRequest, "teams is required, e.g. ?teams=Rhein Fire,Vienna Vikings")
+		return
+	}
+	if len(names) > maxCompareTeams {
+		respondError(c, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("at most %d teams can be compared", maxCompareTeams))
+		return
+	}
+
+	games, err := loadSchedule(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	divisions := divisionMap(leagueFromContext(c.Request.Context()))
+	var teams, unknown []string
+	for _, name := range names {
+		team, ok := findTeam(name, games, divisions)
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		teams = append(teams, team)
+	}
+	if len(unknown) > 0 {
+		respondError(c, http.StatusBadRequest, codeBadRequest, "unknown teams: "+strings.Join(unknown, ", "))
+		return
+	}
+
+	standings, err := getStandings(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	rows := make([]TeamStanding, 0, len(teams))
+	for _, team := range teams {
+		rows = append(rows, findStanding(standings, team, divisions))
+	}
+	respondJSON(c, http.StatusOK, rows)
+}
+
+// unmappedTeams returns the teams playing in the games that aren't in the
+// league's division map, sorted by name. Their standings end up in
+// "UNKNOWN".
+func unmappedTeams(games []Schedule, league string) []string {
+	mapped := make(map[string]bool)
+	for team := range divisionMap(league) {
+		mapped[normalizeTeamName(team)] = true
+	}
+
+	seen := make(map[string]bool)
+	unmapped := []string{}
+	for _, game := range games {
+		for _, team := range []string{game.HomeTeam, game.AwayTeam} {
+			if mapped[normalizeTeamName(team)] || seen[team] {
+				continue
+			}
+			seen[team] = true
+			unmapped = append(unmapped, team)
+		}
+	}
+	sort.Strings(unmapped)
+	return unmapped
+}
+
+// warnUnmappedTeams logs the teams missing from the league's division map
+// so the mapping can be updated
+func warnUnmappedTeams(games []Schedule, league string) {
+	if unmapped := unmappedTeams(games, league); len(unmapped) > 0 {
+		logWarnf("%s teams without a division, add them to the division map: %s", league, strings.Join(unmapped, ", "))
+	}
+}
+
+// getUnmappedTeams lists the scheduled teams of the league that have no
+// division
+func getUnmappedTeams(c *gin.Context) {
+	ctx := c.Request.Context()
+	games, err := loadSchedule(ctx)
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, unmappedTeams(games, leagueFromContext(ctx)))
+}
+
+// TeamListEntry is a team in the list of all teams
+type TeamListEntry struct {
+	TeamName string
+	Division string
+	Wins     int
+	Losses   int
+	Record   string
+	Logo     string `json:",omitempty"` // Logo file name like in the standings
+	Color    string `json:",omitempty"`
+}
+
+// getTeams lists every team of the league's division map with its current
+// record, sorted by division (in GOELF_DIVISIONS order) and name
+func getTeams(c *gin.Context) {
+	ctx := c.Request.Context()
+	standings, err := getStandings(ctx)
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	order := make(map[string]int)
+	for i, division := range config.Divisions {
+		order[division] = i
+	}
+
+	divisions := divisionMap(leagueFromContext(ctx))
+	teams := []TeamListEntry{}
+	for _, team := range knownTeams(divisions) {
+		standing := findStanding(standings, team, divisions)
+		entry := TeamListEntry{
+			TeamName: team,
+			Division: standing.Division,
+			Wins:     standing.Wins,
+			Losses:   standing.Losses,
+			Record:   standing.Record,
+			Logo:     teamLogos[team],
+			Color:    standing.Color,
+		}
+		teams = append(teams, entry)
+	}
+
+	// Divisions missing from GOELF_DIVISIONS go last
+	sort.SliceStable(teams, func(i, j int) bool {
+		oi, iok := order[teams[i].Division]
+		oj, jok := order[teams[j].Division]
+		if iok != jok {
+			return iok
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		if teams[i].Division != teams[j].Division {
+			return teams[i].Division < teams[j].Division
+		}
+		return teams[i].TeamName < teams[j].TeamName
+	})
+
+	c.JSON(http.StatusOK, teams)
+}
+
+// idleTeams returns the teams of the league that have no played game yet:
+// the teams of its unplayed games and of its division map. A division map
+// spelling is skipped if the games already use another spelling of the team.
+func idleTeams(games []Schedule, teamStats map[string]teamRecord, divisions map[string]string) []string {
+	seen := make(map[string]bool)
+	for team := range teamStats {
+		seen[normalizeTeamName(team)] = true
+	}
+
+	var idle []string
+	add := func(team string) {
+		normalized := normalizeTeamName(team)
+		if normalized == "" || seen[normalized] {
+			return
+		}
+		seen[normalized] = true
+		idle = append(idle, team)
+	}
+	for _, game := range games {
+		add(game.HomeTeam)
+		add(game.AwayTeam)
+	}
+	for _, team := range knownTeams(divisions) {
+		add(team)
+	}
+	return idle
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetTeams(t *testing.T) {
+	r := newTestRouter(t)
+	seedGames(t, testGames)
+
+	var teams []TeamListEntry
+	decodeResponse(t, serve(r, http.MethodGet, "/api/teams"), &teams)
+
+	byName := make(map[string]TeamListEntry)
+	for _, team := range teams {
+		byName[team.TeamName] = team
+	}
+	for _, want := range []TeamListEntry{
+		{TeamName: "Rhein Fire", Division: "NORTH", Record: "2-0", Logo: "rhf.png"},
+		{TeamName: "Vienna Vikings", Division: "EAST", Record: "0-0", Logo: "vik.png"}, // No games yet
+	} {
+		got, ok := byName[want.TeamName]
+		if !ok {
+			t.Errorf("%s is missing", want.TeamName)
+			continue
+		}
+		if got.Division != want.Division || got.Record != want.Record || got.Logo != want.Logo {
+			t.Errorf("%s = %+v, want division %s record %s logo %s", want.TeamName, got, want.Division, want.Record, want.Logo)
+		}
+	}
+
+	// Sorted by division in GOELF_DIVISIONS order, then by name
+	divisionOrder := make(map[string]int)
+	for i, division := range config.Divisions {
+		divisionOrder[division] = i
+	}
+	for i := 1; i < len(teams); i++ {
+		prev, cur := teams[i-1], teams[i]
+		if divisionOrder[prev.Division] > divisionOrder[cur.Division] ||
+			(prev.Division == cur.Division && prev.TeamName > cur.TeamName) {
+			t.Errorf("%s (%s) is listed before %s (%s)", prev.TeamName, prev.Division, cur.TeamName, cur.Division)
+		}
+	}
+}
+
+func TestTeamsPerLeague(t *testing.T) {
+	r := newTestRouter(t)
+	seedGames(t, testGames)
+	// The youth league shares team names with the primary league but has
+	// divisions of its own
+	config.Leagues = append(config.Leagues, League{ID: "youth"})
+	leagueDivisions["youth"] = map[string]string{
+		"Rhein Fire":        "U19 WEST",
+		"Berlin Thunder":    "U19 EAST",
+		"Munich Ravens U19": "U19 EAST",
+	}
+
+	youth := []Schedule{
+		mockGame("youth-1", 1, "2024-05-18", "11:00", "Rhein Fire", "Berlin Thunder", "Duisburg", 10, 3),
+		mockGame("youth-2", 2, "2024-05-25", "11:00", "Nordic Storm", "Rhein Fire", "Copenhagen", 6, 20),
+	}
+	if _, err := storeSchedules(withLeague(context.Background(), "youth"), youth, false); err != nil {
+		t.Fatalf("storeSchedules: %v", err)
+	}
+
+	var teams []TeamListEntry
+	decodeResponse(t, serve(r, http.MethodGet, "/api/teams?league=youth"), &teams)
+	var names []string
+	for _, team := range teams {
+		names = append(names, team.TeamName+" "+team.Division)
+	}
+	if got := strings.Join(names, ","); got != "Berlin Thunder U19 EAST,Munich Ravens U19 U19 EAST,Rhein Fire U19 WEST" {
+		t.Errorf("youth teams %s, want only the teams of its division map", got)
+	}
+
+	// The shared teams keep their division in the primary league
+	decodeResponse(t, serve(r, http.MethodGet, "/api/teams"), &teams)
+	for _, team := range teams {
+		if (team.TeamName == "Rhein Fire" || team.TeamName == "Berlin Thunder") && team.Division != "NORTH" {
+			t.Errorf("%s is in %s in the primary league, want NORTH", team.TeamName, team.Division)
+		}
+	}
+
+	var standings []DivisionData
+	decodeResponse(t, serve(r, http.MethodGet, "/api/scoreboard?league=youth"), &standings)
+	if got := findStanding(standings, "Rhein Fire", leagueDivisions["youth"]); got.Division != "U19 WEST" || got.Record != "2-0" {
+		t.Errorf("youth Rhein Fire %s %s, want U19 WEST 2-0", got.Division, got.Record)
+	}
+	decodeResponse(t, serve(r, http.MethodGet, "/api/scoreboard"), &standings)
+	if got := findStanding(standings, "Rhein Fire", teamDivisions); got.Division != "NORTH" || got.Record != "2-0" {
+		t.Errorf("primary Rhein Fire %s %s, want NORTH 2-0", got.Division, got.Record)
+	}
+
+	var scenarios TeamScenarios
+	decodeResponse(t, serve(r, http.MethodGet, "/api/team/Rhein%20Fire/scenarios?league=youth"), &scenarios)
+	if scenarios.Division != "U19 WEST" {
+		t.Errorf("youth scenarios in %s, want U19 WEST", scenarios.Division)
+	}
+
+	// Teams without games are only found in the league that maps them
+	if w := serve(r, http.MethodGet, "/api/team/munich%20ravens%20u19?league=youth"); w.Code != http.StatusOK {
+		t.Errorf("youth team lookup status %d, want 200", w.Code)
+	}
+	if w := serve(r, http.MethodGet, "/api/team/munich%20ravens%20u19"); w.Code != http.StatusNotFound {
+		t.Errorf("primary team lookup status %d, want 404", w.Code)
+	}
+
+	var unmapped []string
+	decodeResponse(t, serve(r, http.MethodGet, "/api/teams/unmapped?league=youth"), &unmapped)
+	if strings.Join(unmapped, ",") != "Nordic Storm" {
+		t.Errorf("youth unmapped teams %v, want [Nordic Storm]", unmapped)
+	}
+}
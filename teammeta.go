@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// teamColors maps team names to their primary color (e.g. "#c8102e"). There
+// are no built-in colors, they only come from the teams table.
+var teamColors = map[string]string{}
+
+// loadTeamMetadata fills the teams table with the built-in divisions and
+// logos of teams it doesn't know yet and then applies the table on top of the
+// built-in maps. Editing a row (e.g. with the sqlite3 CLI) takes effect on the
+// next start. Empty columns keep the built-in value.
+func loadTeamMetadata(ctx context.Context) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for team, division := range teamDivisions {
+		_, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO teams (name, division, logo, color) VALUES (?, ?, ?, '')", team, division, teamLogos[team])
+		if err != nil {
+			return fmt.Errorf("seeding teams: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("seeding teams: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT name, division, logo, color FROM teams")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var name, division, logo, color string
+		if err := rows.Scan(&name, &division, &logo, &color); err != nil {
+			return err
+		}
+		if division != "" {
+			teamDivisions[name] = strings.ToUpper(division)
+		}
+		if logo != "" {
+			teamLogos[name] = logo
+		}
+		if color != "" {
+			teamColors[name] = color
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	logInfof("Loaded metadata of %d teams", count)
+	return nil
+}
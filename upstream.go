@@ -0,0 +1,44 @@
+package main
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tlsVersions are the accepted GOELF_TLS_MIN_VERSION values
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var (
+	upstreamClientOnce sync.Once
+	upstreamClient     *http.Client
+)
+
+// httpClient returns the client shared by all upstream requests, so idle
+// connections are reused across fetches instead of doing a new TLS handshake
+// every time
+func httpClient() *http.Client {
+	upstreamClientOnce.Do(func() {
+		transport := &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          config.HTTPMaxIdleConns,
+			MaxIdleConnsPerHost:   config.HTTPMaxIdleConnsPerHost,
+			IdleConnTimeout:       config.HTTPIdleConnTimeout,
+			TLSClientConfig:       &tls.Config{MinVersion: tlsVersions[config.TLSMinVersion]},
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		}
+		upstreamClient = &http.Client{Transport: transport}
+	})
+	return upstreamClient
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Build information, set at build time with
+// -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD)"
+var (
+	version = "dev"
+	commit  = "unknown"
+)
+
+// apiVersion is the current version of the JSON API, served under /api/<apiVersion>
+const apiVersion = "v1"
+
+// getVersion returns the application and API versions
+func getVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"version":    version,
+		"commit":     commit,
+		"apiVersion": apiVersion,
+	})
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// webFS bundles the templates and assets into the binary
+//
+//go:embed templates assets
+var webFS embed.FS
+
+// htmlEnabled is false when no templates were found, e.g. in API-only deployments
+var htmlEnabled bool
+
+// templateFuncs are the custom functions available in all templates
+var templateFuncs = template.FuncMap{
+	"add": func(a, b int) int {
+		return a + b
+	},
+}
+
+// setupFrontend serves the assets and loads the templates. They are taken
+// from the binary unless GOELF_WEB_DIR points to a directory containing
+// "templates" and "assets", which is handy for live-editing the frontend.
+// GOELF_TEMPLATES overrides where the templates are loaded from, e.g. for
+// a theme.
+func setupFrontend(r *gin.Engine) {
+	if config.WebDir != "" {
+		logInfof("Serving assets from %s", config.WebDir)
+		r.Static("/assets", filepath.Join(config.WebDir, "assets"))
+	} else {
+		assets, err := fs.Sub(webFS, "assets")
+		if err != nil {
+			log.Fatalf("Failed to open embedded assets: %v", err)
+		}
+		r.StaticFS("/assets", http.FS(assets))
+	}
+
+	switch {
+	case config.Templates != "":
+		loadTemplates(r, config.Templates)
+	case config.WebDir != "":
+		loadTemplates(r, filepath.Join(config.WebDir, "templates", "*"))
+	default:
+		tmpl, err := template.New("").Funcs(templateFuncs).ParseFS(webFS, "templates/*")
+		if err != nil {
+			log.Fatalf("Failed to parse embedded templates: %v", err)
+		}
+		r.SetHTMLTemplate(tmpl)
+		htmlEnabled = true
+	}
+}
+
+// reloadableTemplates renders the templates matching a glob and can parse
+// them again while the server is running
+type reloadableTemplates struct {
+	mu      sync.RWMutex
+	pattern string
+	tmpl    *template.Template
+}
+
+// Instance implements render.HTMLRender with the current templates
+func (t *reloadableTemplates) Instance(name string, data interface{}) render.Render {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return render.HTML{Template: t.tmpl, Name: name, Data: data}
+}
+
+// reload parses the templates again. On error the previous templates stay
+// in use.
+func (t *reloadableTemplates) reload() error {
+	tmpl, err := template.New("").Funcs(templateFuncs).ParseGlob(t.pattern)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.tmpl = tmpl
+	t.mu.Unlock()
+	return nil
+}
+
+// loadTemplates loads the HTML templates matching the glob and reloads them
+// on SIGHUP. If there are none, the server runs headless and HTMX requests
+// are answered with JSON.
+func loadTemplates(r *gin.Engine, pattern string) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil || len(matches) == 0 {
+		logWarnf("no templates found for %q, HTML rendering is disabled", pattern)
+		htmlEnabled = false
+		return
+	}
+
+	templates := &reloadableTemplates{pattern: pattern}
+	if err := templates.reload(); err != nil {
+		log.Fatalf("Failed to parse templates %q: %v", pattern, err)
+	}
+	r.HTMLRender = templates
+	htmlEnabled = true
+	logInfof("Loaded templates from %s, send SIGHUP to reload them", pattern)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := templates.reload(); err != nil {
+				logErrorf("Error reloading templates, keeping the previous ones: %v", err)
+				continue
+			}
+			logInfof("Reloaded templates from %s", pattern)
+		}
+	}()
+}
+
+// wantsHTML reports whether the request came from HTMX (has the HX-Request
+// header) and templates are available to render a fragment
+func wantsHTML(c *gin.Context) bool {
+	return htmlEnabled && c.GetHeader("HX-Request") == "true"
+}
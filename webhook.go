@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// webhookAttempts is how often a webhook post is tried before giving up
+const webhookAttempts = 3
+
+// webhookTemplate renders the message posted for a result, parsed from
+// GOELF_WEBHOOK_TEMPLATE by loadConfig
+var webhookTemplate *template.Template
+
+// notifyResults posts a message for each game to the configured webhook in
+// the background. It does nothing if no webhook is configured.
+func notifyResults(games []Schedule) {
+	if config.WebhookURL == "" || len(games) == 0 {
+		return
+	}
+
+	var messages []string
+	for _, game := range games {
+		var b strings.Builder
+		if err := webhookTemplate.Execute(&b, game); err != nil {
+			logErrorf("Error rendering webhook message for %s: %v", game.StatcrewID, err)
+			continue
+		}
+		messages = append(messages, b.String())
+	}
+
+	go func() {
+		for _, message := range messages {
+			postWebhook(message)
+		}
+	}()
+}
+
+// postWebhook sends a message, retrying with an increasing delay. The payload
+// has both the Slack ("text") and the Discord ("content") key.
+func postWebhook(message string) {
+	payload, err := json.Marshal(map[string]string{"text": message, "content": message})
+	if err != nil {
+		logErrorf("Error encoding webhook message: %v", err)
+		return
+	}
+
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		err = sendWebhook(payload)
+		if err == nil {
+			return
+		}
+		if attempt == webhookAttempts {
+			logErrorf("Giving up on webhook after %d attempts: %v", attempt, err)
+			return
+		}
+		logWarnf("Webhook attempt %d failed, retrying in %v: %v", attempt, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// sendWebhook makes a single webhook request
+func sendWebhook(payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", config.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
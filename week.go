@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// How the current week was determined
+const (
+	weekStateCurrent = "current" // Today is within the week's game days
+	weekStateNext    = "next"    // Between weeks or before the season, the next week
+	weekStateLast    = "last"    // After the season, the last week
+)
+
+// CurrentWeek is the game week the UI should show by default
+type CurrentWeek struct {
+	Week  int    `json:"week"`
+	State string `json:"state"`
+}
+
+// currentWeek returns the week whose game days include today, the next week
+// if today is between weeks or before the season, and the last week after
+// the season. Game days are compared as UTC dates. Games without a
+// parseable date are ignored; ok is false if no game has one.
+func currentWeek(games []Schedule, now time.Time) (CurrentWeek, bool) {
+	type span struct{ first, last string }
+	spans := make(map[int]span)
+	for _, game := range games {
+		kickoff, ok := parseGameTime(game)
+		if !ok {
+			continue
+		}
+		day := kickoff.UTC().Format("2006-01-02")
+		s, seen := spans[game.GameWeek]
+		if !seen || day < s.first {
+			s.first = day
+		}
+		if day > s.last {
+			s.last = day
+		}
+		spans[game.GameWeek] = s
+	}
+	if len(spans) == 0 {
+		return CurrentWeek{}, false
+	}
+
+	weeks := make([]int, 0, len(spans))
+	for week := range spans {
+		weeks = append(weeks, week)
+	}
+	sort.Ints(weeks)
+
+	today := now.UTC().Format("2006-01-02")
+	for _, week := range weeks {
+		if s := spans[week]; s.first <= today && today <= s.last {
+			return CurrentWeek{Week: week, State: weekStateCurrent}, true
+		}
+	}
+
+	// Weeks may be numbered out of date order, pick the one starting soonest
+	next := -1
+	for _, week := range weeks {
+		if spans[week].first > today && (next < 0 || spans[week].first < spans[next].first) {
+			next = week
+		}
+	}
+	if next >= 0 {
+		return CurrentWeek{Week: next, State: weekStateNext}, true
+	}
+
+	last := weeks[0]
+	for _, week := range weeks {
+		if spans[week].last >= spans[last].last {
+			last = week
+		}
+	}
+	return CurrentWeek{Week: last, State: weekStateLast}, true
+}
+
+// errInvalidGameWeek is returned by resolveGameWeek for malformed values
+var errInvalidGameWeek = errors.New("game week must be a number or \"current\"")
+
+// resolveGameWeek parses a game week parameter, which is either a number or
+// "current". ok is false for "current" when there are no dated games.
+func resolveGameWeek(ctx context.Context, value string) (week int, ok bool, err error) {
+	if value != "current" {
+		week, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, false, errInvalidGameWeek
+		}
+		return week, true, nil
+	}
+
+	games, err := loadSchedule(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	current, ok := currentWeek(games, time.Now())
+	return current.Week, ok, nil
+}
+
+// getCurrentWeek returns the game week the UI should jump to
+func getCurrentWeek(c *gin.Context) {
+	games, err := loadSchedule(c.Request.Context())
+	if err != nil {
+		respondDBError(c, err)
+		return
+	}
+
+	current, ok := currentWeek(games, time.Now())
+	if !ok {
+		respondError(c, http.StatusNotFound, codeNotFound, "no scheduled games")
+		return
+	}
+	c.JSON(http.StatusOK, current)
+}